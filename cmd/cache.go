@@ -0,0 +1,411 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/cache"
+	"github.com/tom/ligma/internal/config"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and maintain the SPDX data cache",
+	Long: `Inspect and maintain the ~/.ligma/_cache file cache: show its status, list cached IDs, clear or prune
+entries, and verify them against their recorded checksums.`,
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:           "status",
+	Short:         "Show entry count, total size and the age and source of the license list",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runCacheStatus,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cached licenses and exceptions with their ages",
+	Long: `List the cached entries of the configured source and list version with their ages. With --all, list the
+entries of every source and version in the cache.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runCacheLs,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [id...]",
+	Short: "Remove the whole cache, or the cached entries of the given IDs",
+	Long: `Remove every cache entry, or, given IDs, only the cached details of those licenses and exceptions (for every
+source and list version).`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runCacheClear,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:           "prune --older-than <age>",
+	Short:         "Remove cache entries older than an age (e.g. 30d, 12h)",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runCachePrune,
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:           "verify",
+	Short:         "Check every entry's JSON and recorded SHA-256 and remove corrupt ones",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runCacheVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	for _, c := range []*cobra.Command{cacheStatusCmd, cacheLsCmd, cacheClearCmd, cachePruneCmd, cacheVerifyCmd} {
+		cacheCmd.AddCommand(c)
+		c.Flags().BoolP("json", "j", false, "output as JSON")
+	}
+	cacheLsCmd.Flags().Bool("all", false, "list the entries of every source and list version")
+	cachePruneCmd.Flags().String("older-than", "", "minimum age of the entries to remove, e.g. 30d, 2w or 12h (required)")
+}
+
+// cacheRoot returns ~/.ligma/_cache.
+func cacheRoot() (string, error) {
+	dir, err := config.LigmaDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "_cache"), nil
+}
+
+// cacheEntries returns the entries of the whole cache and the cache root.
+func cacheEntries() ([]cache.Entry, string, error) {
+	root, err := cacheRoot()
+	if err != nil {
+		return nil, "", err
+	}
+	entries, err := cache.Entries(root)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrIOOrNetwork, err)
+	}
+	return entries, root, nil
+}
+
+// currentNamespace returns the cache namespace of the configured source and list version, relative
+// to the cache root; "" when the source is not cached (embedded, dir:).
+func currentNamespace(cfg *config.Config, root string) (string, error) {
+	if sourceOverride == nil && sourceName(cfg) != "http" {
+		return "", nil
+	}
+	up, err := upstreamSource(cfg)
+	if err != nil {
+		return "", err
+	}
+	dir, err := cacheDir(cfg, up)
+	if err != nil {
+		return "", err
+	}
+	ns, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(ns), nil
+}
+
+// cacheStatus is the output of cache status.
+type cacheStatus struct {
+	Root    string       `json:"root"`
+	Entries int          `json:"entries"`
+	Bytes   int64        `json:"bytes"`
+	Oldest  *time.Time   `json:"oldest,omitempty"`
+	Newest  *time.Time   `json:"newest,omitempty"`
+	List    *cacheListed `json:"licenseList,omitempty"` // the configured source's cached license list
+}
+
+type cacheListed struct {
+	Source             string    `json:"source,omitempty"`
+	LicenseListVersion string    `json:"licenseListVersion,omitempty"`
+	ModTime            time.Time `json:"modTime"`
+}
+
+func runCacheStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	entries, root, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+	ns, err := currentNamespace(cfg, root)
+	if err != nil {
+		return err
+	}
+	st := cacheStatus{Root: root, Entries: len(entries)}
+	for i, e := range entries {
+		st.Bytes += e.Size
+		if st.Oldest == nil || e.ModTime.Before(*st.Oldest) {
+			st.Oldest = &entries[i].ModTime
+		}
+		if st.Newest == nil || e.ModTime.After(*st.Newest) {
+			st.Newest = &entries[i].ModTime
+		}
+		if e.Namespace == ns && e.Kind == cache.KindLicenseList {
+			st.List = &cacheListed{Source: e.Meta.Source, ModTime: e.ModTime}
+			var list struct {
+				LicenseListVersion string `json:"licenseListVersion"`
+			}
+			if b, err := os.ReadFile(e.Path); err == nil && json.Unmarshal(b, &list) == nil {
+				st.List.LicenseListVersion = list.LicenseListVersion
+			}
+		}
+	}
+
+	if useJSON, _ := cmd.Flags().GetBool("json"); useJSON {
+		return printJSON(st)
+	}
+	fmt.Printf("cache:    %s\n", root)
+	fmt.Printf("entries:  %d (%s)\n", st.Entries, formatBytes(st.Bytes))
+	if st.Entries > 0 {
+		fmt.Printf("ages:     newest %s, oldest %s\n", formatAge(time.Since(*st.Newest)), formatAge(time.Since(*st.Oldest)))
+	}
+	switch {
+	case st.List == nil:
+		fmt.Println("licenses: list not cached for the configured source")
+	default:
+		fmt.Printf("licenses: list version %s, fetched %s ago", orUnknown(st.List.LicenseListVersion), formatAge(time.Since(st.List.ModTime)))
+		if st.List.Source != "" {
+			fmt.Printf(" from %s", strings.Fields(st.List.Source)[0])
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runCacheLs(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	entries, root, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+	if all, _ := cmd.Flags().GetBool("all"); !all {
+		ns, err := currentNamespace(cfg, root)
+		if err != nil {
+			return err
+		}
+		n := 0
+		for _, e := range entries {
+			if e.Namespace == ns {
+				entries[n] = e
+				n++
+			}
+		}
+		entries = entries[:n]
+	}
+
+	if useJSON, _ := cmd.Flags().GetBool("json"); useJSON {
+		return printJSON(nonNil(entries))
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, e := range entries {
+		id := e.ID
+		if id == "" {
+			id = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", id, e.Kind, formatAge(time.Since(e.ModTime)))
+	}
+	return tw.Flush()
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	entries, root, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]bool, len(args))
+	for _, id := range args {
+		ids[id] = true
+	}
+	var removed []cache.Entry
+	for _, e := range entries {
+		if len(ids) > 0 && !ids[e.ID] {
+			continue
+		}
+		if err := cache.Remove(e); err != nil {
+			return fmt.Errorf("%w: %v", ErrIOOrNetwork, err)
+		}
+		removed = append(removed, e)
+	}
+	if len(ids) == 0 {
+		// The entries went under their locks; only lock files and leftovers of failed writes remain.
+		if err := os.RemoveAll(root); err != nil {
+			return fmt.Errorf("%w: clear cache: %v", ErrIOOrNetwork, err)
+		}
+	} else {
+		cache.RemoveEmptyDirs(root)
+	}
+	return printRemoved(cmd, removed)
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	s, _ := cmd.Flags().GetString("older-than")
+	if s == "" {
+		return fmt.Errorf("--older-than is required, e.g. --older-than 30d")
+	}
+	age, err := parseAge(s)
+	if err != nil {
+		return err
+	}
+	entries, root, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+	var removed []cache.Entry
+	for _, e := range entries {
+		if time.Since(e.ModTime) < age {
+			continue
+		}
+		if err := cache.Remove(e); err != nil {
+			return fmt.Errorf("%w: %v", ErrIOOrNetwork, err)
+		}
+		removed = append(removed, e)
+	}
+	cache.RemoveEmptyDirs(root)
+	return printRemoved(cmd, removed)
+}
+
+// cacheCorrupt is an entry removed by cache verify.
+type cacheCorrupt struct {
+	cache.Entry
+	Error string `json:"error"`
+}
+
+func runCacheVerify(cmd *cobra.Command, args []string) error {
+	entries, _, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+	corrupt := []cacheCorrupt{}
+	for _, e := range entries {
+		verr, err := cache.RemoveCorrupt(e)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrIOOrNetwork, err)
+		}
+		if verr == nil {
+			continue
+		}
+		corrupt = append(corrupt, cacheCorrupt{Entry: e, Error: verr.Error()})
+	}
+
+	if useJSON, _ := cmd.Flags().GetBool("json"); useJSON {
+		return printJSON(struct {
+			Checked int            `json:"checked"`
+			Removed []cacheCorrupt `json:"removed"`
+		}{len(entries), corrupt})
+	}
+	for _, c := range corrupt {
+		fmt.Printf("removed %s: %s\n", entryName(c.Entry), c.Error)
+	}
+	fmt.Printf("%d entries checked, %d corrupt removed\n", len(entries), len(corrupt))
+	return nil
+}
+
+// printRemoved reports the entries removed by clear or prune.
+func printRemoved(cmd *cobra.Command, removed []cache.Entry) error {
+	if useJSON, _ := cmd.Flags().GetBool("json"); useJSON {
+		return printJSON(struct {
+			Removed []cache.Entry `json:"removed"`
+		}{nonNil(removed)})
+	}
+	fmt.Printf("removed %d entries\n", len(removed))
+	return nil
+}
+
+// entryName names e in messages: its ID, or its kind for the lists, plus its namespace.
+func entryName(e cache.Entry) string {
+	name := e.ID
+	if name == "" {
+		name = e.Kind + " list"
+	}
+	return name + " (" + e.Namespace + ")"
+}
+
+func printJSON(v any) error {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		return fmt.Errorf("%w: failed to encode JSON: %v", ErrIOOrNetwork, err)
+	}
+	return nil
+}
+
+// nonNil makes a nil slice encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// parseAge parses an age such as "30d", "2w" or any time.ParseDuration string ("12h", "90m").
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v >= 0 {
+				return time.Duration(v) * unit, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q: want e.g. 30d, 2w or 12h", s)
+	}
+	return d, nil
+}
+
+// formatAge renders d in its largest whole unit: 45s, 12m, 3h, 2d.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return strconv.Itoa(int(d/time.Second)) + "s"
+	case d < time.Hour:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	case d < 24*time.Hour:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	}
+	return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+}
+
+// formatBytes renders n in B, KiB or MiB.
+func formatBytes(n int64) string {
+	switch {
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// runCapture runs c's RunE with args and returns its stdout.
+func runCapture(t *testing.T, c *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := c.RunE(c, args)
+	w.Close()
+	os.Stdout = old
+	out, _ := io.ReadAll(r)
+	return string(out), err
+}
+
+// setFlag sets a flag of c for the rest of the test.
+func setFlag(t *testing.T, c *cobra.Command, name, value string) {
+	t.Helper()
+	def := c.Flags().Lookup(name).DefValue
+	if err := c.Flags().Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Flags().Set(name, def) })
+}
+
+// filledCache stubs a source with MIT, ISC and LLVM-exception and caches the license list and all
+// three details through sync. It returns the config dir.
+func filledCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	t.Cleanup(func() { config.SetConfigDirOverride("") })
+	stubSource(t, &spdx.MemorySource{
+		Licenses: &spdx.LicenseList{LicenseListVersion: "3.27", Licenses: []spdx.License{{LicenseID: "MIT"}, {LicenseID: "ISC"}}},
+		LicenseDetailsByID: map[string]*spdx.LicenseDetails{
+			"MIT": {LicenseID: "MIT", LicenseText: "MIT text"},
+			"ISC": {LicenseID: "ISC", LicenseText: "ISC text"},
+		},
+		Exceptions: &spdx.ExceptionList{Exceptions: []spdx.Exception{{LicenseExceptionID: "LLVM-exception"}}},
+		ExceptionDetailsByID: map[string]*spdx.ExceptionDetails{
+			"LLVM-exception": {LicenseExceptionID: "LLVM-exception", LicenseExceptionText: "text"},
+		},
+	})
+	if _, err := runCapture(t, syncCmd); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	return dir
+}
+
+func TestCacheStatus_JSON(t *testing.T) {
+	filledCache(t)
+	setFlag(t, cacheStatusCmd, "json", "true")
+
+	out, err := runCapture(t, cacheStatusCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	var st cacheStatus
+	if err := json.Unmarshal([]byte(out), &st); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\nraw: %s", err, out)
+	}
+	if st.Entries != 5 || st.Bytes == 0 || st.Oldest == nil {
+		t.Errorf("status = %+v, want 5 entries", st)
+	}
+	if st.List == nil || st.List.LicenseListVersion != "3.27" || st.List.Source == "" {
+		t.Errorf("licenseList = %+v", st.List)
+	}
+}
+
+func TestCacheLs(t *testing.T) {
+	dir := filledCache(t)
+	// An entry of another source is only listed with --all.
+	other := filepath.Join(dir, "_cache", "0123456789abcdef", "details")
+	_ = os.MkdirAll(other, 0755)
+	_ = os.WriteFile(filepath.Join(other, "0BSD.json"), []byte(`{}`), 0644)
+
+	out, err := runCapture(t, cacheLsCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "LLVM-exception") || strings.Contains(out, "0BSD") {
+		t.Errorf("cache ls = %q", out)
+	}
+
+	setFlag(t, cacheLsCmd, "all", "true")
+	setFlag(t, cacheLsCmd, "json", "true")
+	out, err = runCapture(t, cacheLsCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	var entries []struct{ ID, Namespace string }
+	if err := json.Unmarshal([]byte(out), &entries); err != nil || len(entries) != 6 || entries[0].ID != "0BSD" {
+		t.Errorf("cache ls --all --json = %s, %v", out, err)
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	dir := filledCache(t)
+
+	out, err := runCapture(t, cacheClearCmd, "MIT", "LLVM-exception")
+	if err != nil || out != "removed 2 entries\n" {
+		t.Fatalf("clear MIT LLVM-exception = %q, %v", out, err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "_cache", "*", "details", "MIT.json*")); len(matches) != 0 {
+		t.Errorf("MIT still cached: %v", matches)
+	}
+
+	out, err = runCapture(t, cacheClearCmd)
+	if err != nil || out != "removed 3 entries\n" {
+		t.Fatalf("clear = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_cache")); !os.IsNotExist(err) {
+		t.Errorf("cache dir after clear: %v", err)
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	dir := filledCache(t)
+	mit, _ := filepath.Glob(filepath.Join(dir, "_cache", "*", "details", "MIT.json"))
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	_ = os.Chtimes(mit[0], old, old)
+
+	if _, err := runCapture(t, cachePruneCmd); err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("prune without --older-than: expected usage error, got %v", err)
+	}
+	setFlag(t, cachePruneCmd, "older-than", "30d")
+	setFlag(t, cachePruneCmd, "json", "true")
+	out, err := runCapture(t, cachePruneCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	var res struct{ Removed []struct{ ID string } }
+	if err := json.Unmarshal([]byte(out), &res); err != nil || len(res.Removed) != 1 || res.Removed[0].ID != "MIT" {
+		t.Errorf("prune --older-than 30d = %s, %v", out, err)
+	}
+}
+
+func TestCacheVerify(t *testing.T) {
+	dir := filledCache(t)
+	isc, _ := filepath.Glob(filepath.Join(dir, "_cache", "*", "details", "ISC.json"))
+	if err := os.WriteFile(isc[0], []byte(`{"licenseId":"ISC","licenseText":"edited"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCapture(t, cacheVerifyCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if !strings.Contains(out, "removed ISC") || !strings.HasSuffix(out, "5 entries checked, 1 corrupt removed\n") {
+		t.Errorf("verify = %q", out)
+	}
+	if _, err := os.Stat(isc[0]); !os.IsNotExist(err) {
+		t.Errorf("corrupt entry not removed: %v", err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{"30d": 30 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour, "90m": 90 * time.Minute} {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "-1d", "soon", "-5h"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q): expected error", in)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	for d, want := range map[time.Duration]string{45 * time.Second: "45s", 12 * time.Minute: "12m", 3 * time.Hour: "3h", 50 * time.Hour: "2d"} {
+		if got := formatAge(d); got != want {
+			t.Errorf("formatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// addStrictFlag registers --strict on a command that takes license IDs.
+func addStrictFlag(c *cobra.Command) {
+	c.Flags().Bool("strict", false, "fail on deprecated SPDX IDs instead of warning")
+}
+
+// checkDeprecated warns on stderr when id is a deprecated SPDX ID (flagged is its isDeprecatedLicenseId),
+// naming the replacement when one is known. With --strict it returns a usage error instead.
+func checkDeprecated(cmd *cobra.Command, id string, flagged bool) error {
+	replacement, deprecated := spdx.Deprecation(id, flagged)
+	if !deprecated {
+		return nil
+	}
+	msg := id + " is a deprecated SPDX ID"
+	if replacement != "" {
+		msg += fmt.Sprintf("; use %s instead", replacement)
+	}
+	if strict, _ := cmd.Flags().GetBool("strict"); strict {
+		return fmt.Errorf("%s (--strict)", msg)
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+	return nil
+}
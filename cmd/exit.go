@@ -12,12 +12,14 @@ import "errors"
 //   - 1 = usage (invalid flags, malformed args, or any error not 2/3)
 //   - 2 = not found (e.g. unknown license ID)
 //   - 3 = I/O or network (e.g. SPDX fetch failure, file write failure when implemented in later stories)
+//   - 4 = exists (write would replace a file with different contents without --force)
 var (
 	ErrNotFound    = errors.New("not found")
 	ErrIOOrNetwork = errors.New("I/O or network error")
+	ErrExists      = errors.New("file exists")
 )
 
-// exitCodeFrom maps an error to exit code 0–3. Used by Execute; extracted for testing.
+// exitCodeFrom maps an error to exit code 0–4. Used by Execute; extracted for testing.
 func exitCodeFrom(err error) int {
 	if err == nil {
 		return 0
@@ -28,5 +30,8 @@ func exitCodeFrom(err error) int {
 	if errors.Is(err, ErrIOOrNetwork) {
 		return 3
 	}
+	if errors.Is(err, ErrExists) {
+		return 4
+	}
 	return 1
 }
@@ -17,6 +17,7 @@ func TestExitCodeFrom(t *testing.T) {
 		{"ErrIOOrNetwork", ErrIOOrNetwork, 3},
 		{"wrapped ErrNotFound", fmt.Errorf("license not found: MIT: %w", ErrNotFound), 2},
 		{"wrapped ErrIOOrNetwork", fmt.Errorf("simulated: %w", ErrIOOrNetwork), 3},
+		{"wrapped ErrExists", fmt.Errorf("refusing to overwrite LICENSE: %w", ErrExists), 4},
 		{"plain error", errors.New("usage: wrong args"), 1},
 		{"other wrapped", fmt.Errorf("outer: %w", errors.New("inner")), 1},
 	}
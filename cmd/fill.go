@@ -0,0 +1,46 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// fillNow is the clock for the default copyright year; swappable for tests.
+var fillNow = time.Now
+
+// addFillFlags registers --holder, --year, --project and --email on a command that outputs license text.
+func addFillFlags(c *cobra.Command) {
+	c.Flags().String("holder", "", "copyright holder for license placeholders (default: holder in config)")
+	c.Flags().String("year", "", "copyright year for license placeholders (default: year in config, else the current year when other values are set)")
+	c.Flags().String("project", "", "project/program name for license placeholders (default: project in config)")
+	c.Flags().String("email", "", "contact email appended to the holder (default: email in config)")
+}
+
+// fillValues returns the placeholder values for cmd: flags override config. When any value is set but
+// the year is not, the current year is used.
+func fillValues(cmd *cobra.Command, cfg *config.Config) spdx.Values {
+	v := spdx.Values{Holder: cfg.Holder, Year: cfg.Year, Project: cfg.Project, Email: cfg.Email}
+	if s, _ := cmd.Flags().GetString("holder"); s != "" {
+		v.Holder = s
+	}
+	if s, _ := cmd.Flags().GetString("year"); s != "" {
+		v.Year = s
+	}
+	if s, _ := cmd.Flags().GetString("project"); s != "" {
+		v.Project = s
+	}
+	if s, _ := cmd.Flags().GetString("email"); s != "" {
+		v.Email = s
+	}
+	if !v.IsZero() && v.Year == "" {
+		v.Year = strconv.Itoa(fillNow().Year())
+	}
+	return v
+}
@@ -9,12 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/tom/ligma/internal/cache"
 	"github.com/tom/ligma/internal/config"
 	"github.com/tom/ligma/internal/spdx"
+	"github.com/tom/ligma/internal/spdx/expression"
 )
 
 var getSimulateIO bool
@@ -23,7 +23,15 @@ var getSimulateIO bool
 var getCmd = &cobra.Command{
 	Use:           "get",
 	Short:          "Output license text by SPDX ID",
-	Long:           `Fetch and print the full license text for the given SPDX license ID.`,
+	Long: `Fetch and print the full license text for the given SPDX license ID. An SPDX license exception (e.g.
+LLVM-exception) prints the exception text, and <license> WITH <exception> prints the license followed by
+the exception. Informal names such as "GPLv3 or later" are resolved as a whole (see ligma resolve), and
+LicenseRef- IDs name custom or organization licenses.
+
+Copyright placeholders such as <year> and <copyright holders> are filled from --holder, --year, --project
+and --email, or their config defaults.
+
+A deprecated ID (e.g. GPL-2.0) prints a warning naming its replacement; --strict makes it an error.`,
 	Args:           cobra.ExactArgs(1),
 	SilenceUsage:   true,
 	SilenceErrors:  true,
@@ -38,29 +46,61 @@ func runGet(cmd *cobra.Command, args []string) error {
 	if getSimulateIO {
 		return fmt.Errorf("simulated I/O error: %w", ErrIOOrNetwork)
 	}
-	dir, err := config.LigmaDir()
+	src, err := cachedSource(cfg)
 	if err != nil {
 		return err
 	}
-	cacheDir := filepath.Join(dir, "_cache")
-	template := spdx.DefaultDetailsURLTemplate
-	if cfg.SPDXGetURLTemplate != "" {
-		template = cfg.SPDXGetURLTemplate
+	ctx := commandContext(cmd)
+	id, e := resolveInput(ctx, src, cfg.Aliases, cfg.Resolve(args[0]))
+	var exception string
+	if e != nil {
+		terms := expression.Terms(e)
+		if len(terms) > 1 {
+			return fmt.Errorf("%q is a license expression, not a single ID; use one ID at a time (check expressions with ligma validate)", id)
+		}
+		// One license, possibly WITH an exception whose text follows it.
+		id, exception = terms[0].License.String(), terms[0].Exception
+	}
+	useJSON, _ := cmd.Flags().GetBool("json")
+	details, isException, err := lookupText(ctx, src, cfg.Aliases, id)
+	var nf *notFoundError
+	if useJSON && errors.As(err, &nf) {
+		// The error goes to stdout as JSON too, with its suggestions, for scripts reading --json.
+		_ = json.NewEncoder(os.Stdout).Encode(struct {
+			Error *notFoundError `json:"error"`
+		}{nf})
 	}
-	id := cfg.Resolve(args[0])
-	text, err := cache.FetchDetails(cmd.Context(), cacheDir, cache.TTL(cfg.CacheTTL), template, id)
 	if err != nil {
-		if errors.Is(err, spdx.ErrNotFound) {
-			return fmt.Errorf("license not found: %s: %w", id, ErrNotFound)
+		return err
+	}
+	if details.LicenseID != "" {
+		id = details.LicenseID // the listed spelling of a case-insensitive match
+	}
+	if err := checkDeprecated(cmd, id, details.IsDeprecatedLicenseID); err != nil {
+		return err
+	}
+	values := fillValues(cmd, cfg)
+	text := spdx.Fill(details, values)
+	if exception != "" {
+		exDetails, err := lookupException(ctx, src, cfg.Aliases, exception)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("fetch license %s: %v: %w", id, err, ErrIOOrNetwork)
+		if exDetails.LicenseID != "" {
+			exception = exDetails.LicenseID
+		}
+		if err := checkDeprecated(cmd, exception, exDetails.IsDeprecatedLicenseID); err != nil {
+			return err
+		}
+		id += " WITH " + exception
+		text = strings.TrimRight(text, "\n") + "\n\n" + spdx.Fill(exDetails, values)
 	}
-	useJSON, _ := cmd.Flags().GetBool("json")
 	if useJSON {
 		out := struct {
 			ID          string `json:"id"`
 			LicenseText string `json:"licenseText"`
-		}{ID: id, LicenseText: text}
+			IsException bool   `json:"isException,omitempty"`
+		}{ID: id, LicenseText: text, IsException: isException}
 		b, err := json.Marshal(out)
 		if err != nil {
 			return fmt.Errorf("%w: failed to encode JSON: %v", ErrIOOrNetwork, err)
@@ -75,6 +115,8 @@ func runGet(cmd *cobra.Command, args []string) error {
 func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.Flags().BoolP("json", "j", false, "output as JSON")
+	addFillFlags(getCmd)
+	addStrictFlag(getCmd)
 	getCmd.Flags().BoolVar(&getSimulateIO, "simulate-io-error", false, "simulate I/O or network error (dev)")
 	_ = getCmd.Flags().MarkHidden("simulate-io-error")
 }
@@ -1,16 +1,18 @@
 package cmd
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/tom/ligma/internal/cache"
 	"github.com/tom/ligma/internal/config"
 	"github.com/tom/ligma/internal/spdx"
 )
@@ -20,11 +22,10 @@ func TestGetRunE_NotFound(t *testing.T) {
 	defer config.SetConfigDirOverride("")
 
 	getSimulateIO = false
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "", spdx.ErrNotFound
-	}
-	defer func() { getSimulateIO = false; cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return nil, spdx.ErrNotFound
+	}})
+	defer func() { getSimulateIO = false }()
 
 	err := getCmd.RunE(getCmd, []string{"x"})
 	if err == nil {
@@ -56,11 +57,9 @@ func TestGetRunE_Success(t *testing.T) {
 	defer config.SetConfigDirOverride("")
 
 	getSimulateIO = false
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "license text", nil
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "license text"}, nil
+	}})
 
 	err := getCmd.RunE(getCmd, []string{"MIT"})
 	if err != nil {
@@ -78,14 +77,12 @@ func TestGetRunE_AliasResolved(t *testing.T) {
 	}
 
 	getSimulateIO = false
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
 		if id != "MIT" {
-			return "", spdx.ErrNotFound
+			return nil, spdx.ErrNotFound
 		}
-		return "from MIT", nil
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+		return &spdx.LicenseDetails{LicenseText: "from MIT"}, nil
+	}})
 
 	// get mit -> resolve to MIT -> fetch MIT -> "from MIT"
 	err := getCmd.RunE(getCmd, []string{"mit"})
@@ -104,11 +101,9 @@ func TestGetRunE_UnknownAliasPassesThrough(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "", spdx.ErrNotFound
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return nil, spdx.ErrNotFound
+	}})
 
 	err := getCmd.RunE(getCmd, []string{"notanalias"})
 	if err == nil {
@@ -123,11 +118,9 @@ func TestGetRunE_JSON(t *testing.T) {
 	config.SetConfigDirOverride(t.TempDir())
 	defer config.SetConfigDirOverride("")
 
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "license text", nil
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "license text"}, nil
+	}})
 
 	_ = getCmd.Flags().Set("json", "true")
 	defer func() { _ = getCmd.Flags().Set("json", "false") }()
@@ -158,11 +151,9 @@ func TestGetRunE_NoJSONPlainText(t *testing.T) {
 	config.SetConfigDirOverride(t.TempDir())
 	defer config.SetConfigDirOverride("")
 
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "license text", nil
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "license text"}, nil
+	}})
 
 	_ = getCmd.Flags().Set("json", "false")
 
@@ -189,18 +180,17 @@ func TestGetRunE_UsesConfigGetURLTemplate(t *testing.T) {
 	config.SetConfigDirOverride(dir)
 	defer config.SetConfigDirOverride("")
 
-	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spdx_get_url_template":"https://example.com/details/{id}.json"}`), 0644); err != nil {
-		t.Fatal(err)
-	}
-
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		if template != "https://example.com/details/{id}.json" {
-			return "", spdx.ErrNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/details/MIT.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
 		}
-		return "ok", nil
+		_, _ = w.Write([]byte(`{"licenseId":"MIT","licenseText":"ok"}`))
+	}))
+	defer srv.Close()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spdx_get_url_template":"`+srv.URL+`/details/{id}.json"}`), 0644); err != nil {
+		t.Fatal(err)
 	}
-	defer func() { cache.FetchDetailsFn = save }()
 
 	err := getCmd.RunE(getCmd, []string{"MIT"})
 	if err != nil {
@@ -212,11 +202,9 @@ func TestGetRunE_FetchErrorMapsToIOOrNetwork(t *testing.T) {
 	config.SetConfigDirOverride(t.TempDir())
 	defer config.SetConfigDirOverride("")
 
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "", fmt.Errorf("connection refused")
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return nil, fmt.Errorf("connection refused")
+	}})
 
 	err := getCmd.RunE(getCmd, []string{"MIT"})
 	if err == nil {
@@ -226,3 +214,170 @@ func TestGetRunE_FetchErrorMapsToIOOrNetwork(t *testing.T) {
 		t.Errorf("RunE: expected ErrIOOrNetwork, got %v", err)
 	}
 }
+
+func TestGetRunE_ExceptionFallback(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &spdx.MemorySource{
+		LicenseDetailsByID: map[string]*spdx.LicenseDetails{},
+		ExceptionDetailsByID: map[string]*spdx.ExceptionDetails{
+			"LLVM-exception": {LicenseExceptionID: "LLVM-exception", LicenseExceptionText: "exception text"},
+		},
+	})
+
+	_ = getCmd.Flags().Set("json", "true")
+	defer func() { _ = getCmd.Flags().Set("json", "false") }()
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := getCmd.RunE(getCmd, []string{"LLVM-exception"})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	var v struct {
+		ID          string `json:"id"`
+		LicenseText string `json:"licenseText"`
+		IsException bool   `json:"isException"`
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\nraw: %s", err, out)
+	}
+	if v.ID != "LLVM-exception" || v.LicenseText != "exception text" || !v.IsException {
+		t.Errorf("JSON = %+v", v)
+	}
+}
+
+func TestGetRunE_NeitherLicenseNorException(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &funcSource{exception: func(id string) (*spdx.ExceptionDetails, error) {
+		return nil, fmt.Errorf("connection refused")
+	}})
+
+	// the exception lookup failing for another reason must not hide the license 404
+	err := getCmd.RunE(getCmd, []string{"Nope"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("RunE: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetRunE_ExpressionIsUsageError(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		t.Fatalf("fetcher called with expression %q", id)
+		return nil, nil
+	}})
+
+	err := getCmd.RunE(getCmd, []string{"MIT OR Apache-2.0"})
+	if err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("RunE: expected usage error, got %v", err)
+	}
+}
+
+func TestGetRunE_FillsPlaceholdersDefaultYear(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "Copyright (c) <year> <copyright holders>"}, nil
+	}})
+	saveNow := fillNow
+	fillNow = func() time.Time { return time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { fillNow = saveNow }()
+
+	_ = getCmd.Flags().Set("holder", "Acme")
+	defer func() { _ = getCmd.Flags().Set("holder", "") }()
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := getCmd.RunE(getCmd, []string{"MIT"})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	if string(out) != "Copyright (c) 2031 Acme" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestGetRunE_DeprecatedID(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseID: id, LicenseText: "text", IsDeprecatedLicenseID: id != "MIT"}, nil
+	}})
+
+	r, w, _ := os.Pipe()
+	old := os.Stderr
+	os.Stderr = w
+	out, err := runCapture(t, getCmd, "GPL-2.0+")
+	w.Close()
+	os.Stderr = old
+	if err != nil || out != "text" {
+		t.Fatalf("RunE = %q, %v", out, err)
+	}
+	if stderr, _ := io.ReadAll(r); string(stderr) != "warning: GPL-2.0+ is a deprecated SPDX ID; use GPL-2.0-or-later instead\n" {
+		t.Errorf("stderr = %q", stderr)
+	}
+
+	setFlag(t, getCmd, "strict", "true")
+	if _, err := runCapture(t, getCmd, "Nunit"); err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("--strict: expected usage error, got %v", err)
+	}
+	if _, err := runCapture(t, getCmd, "MIT"); err != nil {
+		t.Errorf("--strict MIT: %v", err)
+	}
+}
+
+func TestGetRunE_CaseInsensitive(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+	setFlag(t, getCmd, "json", "true")
+
+	out, err := runCapture(t, getCmd, "apache-2.0")
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if out != `{"id":"Apache-2.0","licenseText":"Apache-2.0 text"}` {
+		t.Errorf("get --json apache-2.0 = %s", out)
+	}
+}
+
+func TestGetRunE_NotFoundSuggests(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"aliases":{"expat":"MIT"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+
+	_, err := runCapture(t, getCmd, "Apahce-2.0")
+	if !errors.Is(err, ErrNotFound) || err.Error() != "license not found: Apahce-2.0 (did you mean Apache-2.0?)" {
+		t.Errorf("RunE(Apahce-2.0) = %v", err)
+	}
+	if _, err := runCapture(t, getCmd, "expta"); err == nil || !strings.Contains(err.Error(), "did you mean MIT?") {
+		t.Errorf("RunE(expta): expected the alias's license suggested, got %v", err)
+	}
+
+	setFlag(t, getCmd, "json", "true")
+	out, err := runCapture(t, getCmd, "Apahce-2.0")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("RunE --json: expected ErrNotFound, got %v", err)
+	}
+	if out != `{"error":{"kind":"license","id":"Apahce-2.0","suggestions":["Apache-2.0"]}}`+"\n" {
+		t.Errorf("get --json Apahce-2.0 = %s", out)
+	}
+}
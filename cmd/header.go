@@ -0,0 +1,69 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/comment"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// headerCmd represents the header command
+var headerCmd = &cobra.Command{
+	Use:   "header <id>",
+	Short: "Print the standard license header for source files",
+	Long: `Print the SPDX standard license header (the "how to apply" notice, e.g. for Apache-2.0 or GPL) for the
+given license ID, with copyright placeholders filled like get/write. Use --comment-style to wrap it as a source
+comment. Exits with code 2 if the license is unknown or has no standard header.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runHeader,
+}
+
+func runHeader(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	style, _ := cmd.Flags().GetString("comment-style")
+	if style != "" {
+		// Reject a bad style before any fetch.
+		if _, err := comment.Wrap("", style); err != nil {
+			return err
+		}
+	}
+	src, err := cachedSource(cfg)
+	if err != nil {
+		return err
+	}
+	id := cfg.Resolve(args[0])
+	details, _, err := lookupText(commandContext(cmd), src, cfg.Aliases, id)
+	if err != nil {
+		return err
+	}
+	if details.LicenseID != "" {
+		id = details.LicenseID
+	}
+	if strings.TrimSpace(details.StandardLicenseHeader) == "" {
+		return fmt.Errorf("license %s has no standard license header: %w", id, ErrNotFound)
+	}
+	text := spdx.FillHeader(details, fillValues(cmd, cfg))
+	if style != "" {
+		text, _ = comment.Wrap(text, style)
+	}
+	_, _ = os.Stdout.WriteString(text)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(headerCmd)
+	headerCmd.Flags().String("comment-style", "", "wrap the header as a source comment: "+strings.Join(comment.Styles(), ", "))
+	addFillFlags(headerCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+func TestHeaderRunE_CommentStyleAndFill(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "full text", StandardLicenseHeader: "Copyright [yyyy] [name of copyright owner]\n\nLicensed under the Apache License, Version 2.0\n"}, nil
+	}})
+
+	_ = headerCmd.Flags().Set("comment-style", "go")
+	_ = headerCmd.Flags().Set("holder", "Acme")
+	_ = headerCmd.Flags().Set("year", "2026")
+	defer func() {
+		_ = headerCmd.Flags().Set("comment-style", "")
+		_ = headerCmd.Flags().Set("holder", "")
+		_ = headerCmd.Flags().Set("year", "")
+	}()
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := headerCmd.RunE(headerCmd, []string{"Apache-2.0"})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	want := "// Copyright 2026 Acme\n//\n// Licensed under the Apache License, Version 2.0\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestHeaderRunE_NoHeaderIsNotFound(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "MIT text"}, nil
+	}})
+
+	err := headerCmd.RunE(headerCmd, []string{"MIT"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("RunE: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestHeaderRunE_UnknownCommentStyle(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	_ = headerCmd.Flags().Set("comment-style", "cobol")
+	defer func() { _ = headerCmd.Flags().Set("comment-style", "") }()
+
+	err := headerCmd.RunE(headerCmd, []string{"MIT"})
+	if err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("RunE: expected usage error, got %v", err)
+	}
+}
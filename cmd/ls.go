@@ -5,40 +5,39 @@ Copyright © 2026 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/tom/ligma/internal/cache"
 	"github.com/tom/ligma/internal/config"
 	"github.com/tom/ligma/internal/spdx"
 )
 
 // popularIDs is the static set of "popular" SPDX IDs for --popular. Order preserved in output.
-var popularIDs = []string{"MIT", "Apache-2.0", "GPL-2.0", "BSD-3-Clause", "ISC"}
+var popularIDs = []string{"MIT", "Apache-2.0", "GPL-2.0-only", "BSD-3-Clause", "ISC"}
 
 // lsCmd represents the ls command
 var lsCmd = &cobra.Command{
 	Use:          "ls",
 	Short:        "List all available SPDX licenses",
-	Long:         `Fetch and list all available SPDX license identifiers from the official SPDX license list.`,
+	Long:         `Fetch and list all available SPDX license identifiers from the official SPDX license list. With --exceptions, list SPDX license exceptions (e.g. LLVM-exception) instead. --no-deprecated hides deprecated IDs. The licenses of the organization registry (org_registry_url) are listed after the SPDX ones, marked (org), and custom LicenseRef- licenses from ~/.ligma/licenses and the project's .ligma/licenses after those, marked (custom).`,
 	SilenceUsage: true,
 	SilenceErrors: true,
 	RunE:         runLs,
 }
 
-// lsListURLOverride, when non-empty, is used instead of spdx.DefaultListURL (for tests).
+// lsListURLOverride, when non-empty, is used instead of the configured list URL (for tests).
 var lsListURLOverride string
 
 func init() {
 	rootCmd.AddCommand(lsCmd)
 	lsCmd.Flags().BoolP("json", "j", false, "output as JSON")
 	lsCmd.Flags().String("filter", "", "case-insensitive filter on license ID or name")
-	lsCmd.Flags().Bool("popular", false, "restrict to a popular set (MIT, Apache-2.0, GPL-2.0, BSD-3-Clause, ISC)")
+	lsCmd.Flags().Bool("popular", false, "restrict to a popular set (MIT, Apache-2.0, GPL-2.0-only, BSD-3-Clause, ISC)")
+	lsCmd.Flags().Bool("no-deprecated", false, "hide deprecated SPDX IDs (e.g. GPL-2.0)")
+	lsCmd.Flags().Bool("exceptions", false, "list SPDX license exceptions instead of licenses")
 }
 
 func runLs(cmd *cobra.Command, args []string) error {
@@ -46,58 +45,129 @@ func runLs(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	dir, err := config.LigmaDir()
+	if lsListURLOverride != "" {
+		cfg.SPDXListURL, cfg.SPDXListMirrors = lsListURLOverride, nil
+	}
+	src, err := cachedSource(cfg)
 	if err != nil {
 		return err
 	}
-	cacheDir := filepath.Join(dir, "_cache")
-	url := spdx.DefaultListURL
-	if lsListURLOverride != "" {
-		url = lsListURLOverride
-	} else if cfg.SPDXListURL != "" {
-		url = cfg.SPDXListURL
+	if ok, _ := cmd.Flags().GetBool("exceptions"); ok {
+		if popular, _ := cmd.Flags().GetBool("popular"); popular {
+			return fmt.Errorf("--popular cannot be used with --exceptions")
+		}
+		return runLsExceptions(cmd, src)
 	}
-	ctx := context.Background()
-	list, err := cache.FetchList(ctx, cacheDir, cache.TTL(cfg.CacheTTL), url)
+	list, err := src.LicenseList(commandContext(cmd))
 	if err != nil {
 		return fmt.Errorf("%w: failed to fetch license list: %v", ErrIOOrNetwork, err)
 	}
-	// Apply --popular first, then --filter (both before output formatting).
+	licenses := list.Licenses
+	// Apply --popular first, then --no-deprecated and --filter (all before output formatting).
 	if ok, _ := cmd.Flags().GetBool("popular"); ok {
 		set := make(map[string]bool)
 		for _, id := range popularIDs {
 			set[id] = true
 		}
 		n := 0
-		for _, l := range list {
+		for _, l := range licenses {
 			if set[l.LicenseID] {
-				list[n] = l
+				licenses[n] = l
+				n++
+			}
+		}
+		licenses = licenses[:n]
+	}
+	if hide, _ := cmd.Flags().GetBool("no-deprecated"); hide {
+		n := 0
+		for _, l := range licenses {
+			if _, deprecated := spdx.Deprecation(l.LicenseID, l.IsDeprecatedLicenseID); !deprecated {
+				licenses[n] = l
 				n++
 			}
 		}
-		list = list[:n]
+		licenses = licenses[:n]
 	}
 	if term, _ := cmd.Flags().GetString("filter"); term != "" {
 		term = strings.ToLower(term)
 		n := 0
-		for _, l := range list {
+		for _, l := range licenses {
 			if strings.Contains(strings.ToLower(l.LicenseID), term) || strings.Contains(strings.ToLower(l.Name), term) {
-				list[n] = l
+				licenses[n] = l
 				n++
 			}
 		}
-		list = list[:n]
+		licenses = licenses[:n]
 	}
 	useJSON, _ := cmd.Flags().GetBool("json")
 	if useJSON {
+		// Same shape as SPDX licenses.json: list version and release date, plus the (filtered) licenses.
+		out := spdx.LicenseList{
+			LicenseListVersion: list.LicenseListVersion,
+			Licenses:           licenses,
+			ReleaseDate:        list.ReleaseDate,
+		}
 		enc := json.NewEncoder(os.Stdout)
-		if err := enc.Encode(list); err != nil {
+		if err := enc.Encode(out); err != nil {
+			return fmt.Errorf("%w: failed to encode JSON: %v", ErrIOOrNetwork, err)
+		}
+		return nil
+	}
+	for _, l := range licenses {
+		switch {
+		case l.IsCustom:
+			fmt.Println(l.LicenseID + " (custom)")
+		case l.IsOrg:
+			fmt.Println(l.LicenseID + " (org)")
+		default:
+			fmt.Println(l.LicenseID)
+		}
+	}
+	return nil
+}
+
+// runLsExceptions is ls --exceptions: same --no-deprecated, --filter and --json handling as licenses, over exceptions.json.
+func runLsExceptions(cmd *cobra.Command, src spdx.Source) error {
+	list, err := src.ExceptionList(commandContext(cmd))
+	if err != nil {
+		return fmt.Errorf("%w: failed to fetch exception list: %v", ErrIOOrNetwork, err)
+	}
+	exceptions := list.Exceptions
+	if hide, _ := cmd.Flags().GetBool("no-deprecated"); hide {
+		n := 0
+		for _, e := range exceptions {
+			if _, deprecated := spdx.Deprecation(e.LicenseExceptionID, e.IsDeprecatedLicenseID); !deprecated {
+				exceptions[n] = e
+				n++
+			}
+		}
+		exceptions = exceptions[:n]
+	}
+	if term, _ := cmd.Flags().GetString("filter"); term != "" {
+		term = strings.ToLower(term)
+		n := 0
+		for _, e := range exceptions {
+			if strings.Contains(strings.ToLower(e.LicenseExceptionID), term) || strings.Contains(strings.ToLower(e.Name), term) {
+				exceptions[n] = e
+				n++
+			}
+		}
+		exceptions = exceptions[:n]
+	}
+	useJSON, _ := cmd.Flags().GetBool("json")
+	if useJSON {
+		out := spdx.ExceptionList{
+			LicenseListVersion: list.LicenseListVersion,
+			Exceptions:         exceptions,
+			ReleaseDate:        list.ReleaseDate,
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
 			return fmt.Errorf("%w: failed to encode JSON: %v", ErrIOOrNetwork, err)
 		}
 		return nil
 	}
-	for _, l := range list {
-		fmt.Println(l.LicenseID)
+	for _, e := range exceptions {
+		fmt.Println(e.LicenseExceptionID)
 	}
 	return nil
 }
@@ -11,17 +11,18 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/tom/ligma/internal/cache"
 	"github.com/tom/ligma/internal/config"
 	"github.com/tom/ligma/internal/spdx"
 )
 
-const lsGoodJSON = `{"licenseListVersion":"1.0","licenses":[{"licenseId":"MIT","name":"MIT License"}]}`
+const lsGoodJSON = `{"licenseListVersion":"1.0","releaseDate":"2024-05-22","licenses":[{"licenseId":"MIT","name":"MIT License","isOsiApproved":true,"isFsfLibre":true,"seeAlso":["https://opensource.org/license/mit/"]}]}`
 
 // lsMultiJSON has the 5 popular IDs plus X for --popular and --filter tests.
 const lsMultiJSON = `{"licenses":[
   {"licenseId":"MIT","name":"MIT License"},
   {"licenseId":"Apache-2.0","name":"Apache License 2.0"},
-  {"licenseId":"GPL-2.0","name":"GNU General Public License v2.0"},
+  {"licenseId":"GPL-2.0-only","name":"GNU General Public License v2.0"},
   {"licenseId":"BSD-3-Clause","name":"BSD 3-Clause"},
   {"licenseId":"ISC","name":"ISC License"},
   {"licenseId":"X","name":"X License"}
@@ -48,7 +49,11 @@ func TestLsRunE_Success(t *testing.T) {
 }
 
 func TestLsRunE_FetchErrorMapsToIOOrNetwork(t *testing.T) {
-	config.SetConfigDirOverride(t.TempDir())
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"http_retries":0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
 	defer config.SetConfigDirOverride("")
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -57,7 +62,9 @@ func TestLsRunE_FetchErrorMapsToIOOrNetwork(t *testing.T) {
 	defer srv.Close()
 
 	lsListURLOverride = srv.URL
-	defer func() { lsListURLOverride = "" }()
+	saveFallback := offlineFallback
+	offlineFallback = nil
+	defer func() { lsListURLOverride = ""; offlineFallback = saveFallback }()
 
 	err := lsCmd.RunE(lsCmd, []string{})
 	if err == nil {
@@ -123,13 +130,19 @@ func TestLsRunE_JSON(t *testing.T) {
 	}
 
 	out, _ := io.ReadAll(r)
-	var list []spdx.License
+	var list spdx.LicenseList
 	if err := json.Unmarshal(out, &list); err != nil {
 		t.Fatalf("stdout is not valid JSON: %v\nraw: %s", err, out)
 	}
-	if len(list) != 1 || list[0].LicenseID != "MIT" || list[0].Name != "MIT License" {
+	if list.LicenseListVersion != "1.0" || list.ReleaseDate != "2024-05-22" {
+		t.Errorf("json version/releaseDate = %q/%q", list.LicenseListVersion, list.ReleaseDate)
+	}
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "MIT" || list.Licenses[0].Name != "MIT License" {
 		t.Errorf("json list = %+v", list)
 	}
+	if l := list.Licenses[0]; !l.IsOsiApproved || !l.IsFsfLibre || len(l.SeeAlso) != 1 {
+		t.Errorf("json license metadata = %+v", l)
+	}
 }
 
 func TestLsRunE_NoJSONHumanFormat(t *testing.T) {
@@ -265,7 +278,7 @@ func TestLsRunE_Popular(t *testing.T) {
 	for _, s := range lines {
 		got[s] = true
 	}
-	want := map[string]bool{"MIT": true, "Apache-2.0": true, "GPL-2.0": true, "BSD-3-Clause": true, "ISC": true}
+	want := map[string]bool{"MIT": true, "Apache-2.0": true, "GPL-2.0-only": true, "BSD-3-Clause": true, "ISC": true}
 	if len(got) != 5 || got["X"] {
 		t.Errorf("--popular: got %v, want exactly the 5 popular (no X)", got)
 	}
@@ -309,9 +322,9 @@ func TestLsRunE_PopularAndFilter(t *testing.T) {
 	for _, s := range lines {
 		got[s] = true
 	}
-	// Popular ∩ filter "2": Apache-2.0, GPL-2.0
-	if len(got) != 2 || !got["Apache-2.0"] || !got["GPL-2.0"] {
-		t.Errorf("--popular --filter 2: got %v, want {Apache-2.0, GPL-2.0}", got)
+	// Popular ∩ filter "2": Apache-2.0, GPL-2.0-only
+	if len(got) != 2 || !got["Apache-2.0"] || !got["GPL-2.0-only"] {
+		t.Errorf("--popular --filter 2: got %v, want {Apache-2.0, GPL-2.0-only}", got)
 	}
 }
 
@@ -329,7 +342,7 @@ func TestLsRunE_FilterMatchesName(t *testing.T) {
 	lsListURLOverride = srv.URL
 	defer func() { lsListURLOverride = "" }()
 
-	// "gnu" matches "GNU General Public License v2.0" (name) but not "GPL-2.0" (licenseId)
+	// "gnu" matches "GNU General Public License v2.0" (name) but not "GPL-2.0-only" (licenseId)
 	_ = lsCmd.Flags().Set("filter", "gnu")
 	defer func() { _ = lsCmd.Flags().Set("filter", "") }()
 
@@ -343,7 +356,218 @@ func TestLsRunE_FilterMatchesName(t *testing.T) {
 		t.Fatalf("RunE: %v", err)
 	}
 	out, _ := io.ReadAll(r)
-	if string(out) != "GPL-2.0\n" {
-		t.Errorf("--filter gnu (matches name only): got %q, want GPL-2.0\\n", out)
+	if string(out) != "GPL-2.0-only\n" {
+		t.Errorf("--filter gnu (matches name only): got %q, want GPL-2.0-only\\n", out)
+	}
+}
+
+func TestLsRunE_Exceptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"licenseListVersion":"3.24","exceptions":[{"licenseExceptionId":"LLVM-exception","name":"LLVM Exception"},{"licenseExceptionId":"Classpath-exception-2.0","name":"Classpath exception 2.0"}]}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spdx_exceptions_url":"`+srv.URL+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+
+	_ = lsCmd.Flags().Set("exceptions", "true")
+	_ = lsCmd.Flags().Set("filter", "llvm")
+	defer func() { _ = lsCmd.Flags().Set("exceptions", "false"); _ = lsCmd.Flags().Set("filter", "") }()
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := lsCmd.RunE(lsCmd, []string{})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	if string(out) != "LLVM-exception\n" {
+		t.Errorf("--exceptions --filter llvm: got %q, want LLVM-exception\\n", out)
+	}
+}
+
+func TestLsRunE_EmbeddedSnapshotWhenOffline(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"http_retries":0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	lsListURLOverride = srv.URL
+	defer func() { lsListURLOverride = "" }()
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := lsCmd.RunE(lsCmd, []string{})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: expected the embedded snapshot, got %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	if !strings.Contains(string(out), "MIT\n") {
+		t.Errorf("output = %q, want the snapshot's licenses", out)
+	}
+}
+
+func TestLsRunE_ListVersionPinsURLAndCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3.24.0/licenses.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"licenseListVersion":"3.24","licenses":[{"licenseId":"MIT","name":"MIT License"}]}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	body := `{"spdx_list_url":"` + srv.URL + `/{version}/licenses.json","license_list_version":"3.24"}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+
+	_ = lsCmd.Flags().Set("json", "true")
+	defer func() { _ = lsCmd.Flags().Set("json", "false") }()
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := lsCmd.RunE(lsCmd, []string{})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	var list spdx.LicenseList
+	if err := json.Unmarshal(out, &list); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\nraw: %s", err, out)
+	}
+	if list.LicenseListVersion != "3.24" {
+		t.Errorf("licenseListVersion = %q, want 3.24", list.LicenseListVersion)
+	}
+	metas, _ := filepath.Glob(filepath.Join(dir, "_cache", "*", "list.json.meta"))
+	if len(metas) != 1 {
+		t.Fatalf("cached lists = %v, want one", metas)
+	}
+	var meta cache.Meta
+	if b, err := os.ReadFile(metas[0]); err != nil || json.Unmarshal(b, &meta) != nil || meta.ListVersion != "v3.24.0" {
+		t.Errorf("pinned list meta = %+v, %v", meta, err)
+	}
+
+	// the flag overrides the config key
+	listVersionFlag = "3.25"
+	defer func() { listVersionFlag = "" }()
+	saveFallback := offlineFallback
+	offlineFallback = nil
+	defer func() { offlineFallback = saveFallback }()
+	if err := lsCmd.RunE(lsCmd, []string{}); err == nil {
+		t.Error("RunE with --list-version 3.25: expected the 3.25 URL to be fetched and fail")
+	}
+}
+
+func TestLsRunE_ListMirrors(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(lsGoodJSON))
+	}))
+	defer up.Close()
+
+	dir := t.TempDir()
+	body := `{"http_retries":0,"spdx_list_url":["` + down.URL + `","` + up.URL + `"]}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	saveFallback := offlineFallback
+	offlineFallback = nil
+	defer func() { offlineFallback = saveFallback }()
+
+	if err := lsCmd.RunE(lsCmd, []string{}); err != nil {
+		t.Errorf("RunE: expected the second mirror to answer, got %v", err)
+	}
+}
+
+func TestLsRunE_CacheFollowsConfiguredURL(t *testing.T) {
+	serve := func(id string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"licenses":[{"licenseId":"` + id + `","name":"` + id + `"}]}`))
+		}))
+	}
+	a, b := serve("FROM-A"), serve("FROM-B")
+	defer a.Close()
+	defer b.Close()
+
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	ls := func(url string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spdx_list_url":"`+url+`"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		r, w, _ := os.Pipe()
+		old := os.Stdout
+		os.Stdout = w
+		err := lsCmd.RunE(lsCmd, []string{})
+		w.Close()
+		os.Stdout = old
+		if err != nil {
+			t.Fatalf("RunE: %v", err)
+		}
+		out, _ := io.ReadAll(r)
+		return string(out)
+	}
+
+	if out := ls(a.URL); !strings.Contains(out, "FROM-A") {
+		t.Fatalf("first ls = %q", out)
+	}
+	// Within the TTL, but another source: its own cache entry, not A's.
+	if out := ls(b.URL); !strings.Contains(out, "FROM-B") {
+		t.Errorf("ls after changing spdx_list_url = %q, want data from the new URL", out)
+	}
+	if out := ls(a.URL); !strings.Contains(out, "FROM-A") {
+		t.Errorf("ls back on the first URL = %q", out)
+	}
+}
+
+func TestLsRunE_NoDeprecated(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &spdx.MemorySource{Licenses: &spdx.LicenseList{Licenses: []spdx.License{
+		{LicenseID: "GPL-2.0", IsDeprecatedLicenseID: true},
+		{LicenseID: "GPL-2.0-only"},
+		{LicenseID: "LGPL-2.1"}, // deprecated by the replacement map alone
+		{LicenseID: "MIT"},
+	}}})
+	setFlag(t, lsCmd, "no-deprecated", "true")
+
+	out, err := runCapture(t, lsCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if out != "GPL-2.0-only\nMIT\n" {
+		t.Errorf("ls --no-deprecated = %q", out)
 	}
 }
@@ -0,0 +1,132 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+	"github.com/tom/ligma/internal/spdx/expression"
+)
+
+// maxResolveMatches is how many matches resolve prints by default.
+const maxResolveMatches = 5
+
+// minResolveConfidence is the confidence at which get and write use the best match of an unknown ID
+// instead of failing.
+const minResolveConfidence = 0.8
+
+// resolveCmd represents the resolve command
+var resolveCmd = &cobra.Command{
+	Use:   "resolve <text>",
+	Short: "Map an informal license name or URL to SPDX IDs",
+	Long: `Match informal text such as "Apache License 2.0", "GPLv3", "BSD 3 clause", "new BSD" or a license URL
+(https://opensource.org/licenses/MIT) onto SPDX license IDs. Prints the best matches with a confidence
+between 0 and 1, best first. Several arguments are joined with spaces. Exit code 2 when nothing matches.`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runResolve,
+}
+
+func runResolve(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	src, err := cachedSource(cfg)
+	if err != nil {
+		return err
+	}
+	list, err := src.LicenseList(commandContext(cmd))
+	if err != nil {
+		return fmt.Errorf("%w: failed to fetch license list: %v", ErrIOOrNetwork, err)
+	}
+	text := strings.Join(args, " ")
+	matches := spdx.Resolve(cfg.Resolve(text), list.Licenses)
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	if useJSON, _ := cmd.Flags().GetBool("json"); useJSON {
+		if err := printJSON(nonNil(matches)); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, m := range matches {
+			fmt.Fprintf(tw, "%s\t%.2f\t%s\n", m.ID, m.Confidence, m.Name)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no SPDX license matches %q: %w", text, ErrNotFound)
+	}
+	return nil
+}
+
+// confidentMatch returns the best match of text among licenses when it is at least
+// minResolveConfidence and better than the runner-up.
+func confidentMatch(text string, licenses []spdx.License) (spdx.Match, bool) {
+	matches := spdx.Resolve(text, licenses)
+	if len(matches) == 0 || matches[0].Confidence < minResolveConfidence {
+		return spdx.Match{}, false
+	}
+	if len(matches) > 1 && matches[1].Confidence == matches[0].Confidence {
+		return spdx.Match{}, false
+	}
+	return matches[0], true
+}
+
+// resolveInput returns the license expression that the id or informal text given to get and write
+// stands for, parsed (nil when it does not parse). An expression whose every license and exception is
+// a listed ID (in any case) or an alias is taken as is; other text is first resolved as a whole, since
+// informal names may contain "or" and "with" ("GPLv3 or later", "GPL-2.0 with classpath exception").
+// Text that does not resolve confidently is taken as is, for its unknown IDs to be reported. A single
+// ID is taken as is without reading the lists: lookupText resolves it when it is not found.
+func resolveInput(ctx context.Context, src spdx.Source, aliases map[string]string, text string) (string, expression.Expr) {
+	e, err := expression.Parse(text)
+	if _, single := e.(*expression.License); err == nil && single {
+		return text, e
+	}
+	ix := loadIDIndex(ctx, src, aliases)
+	if err == nil && knownTerms(e, ix, aliases) {
+		return text, e
+	}
+	if m, ok := confidentMatch(text, ix.list); ok {
+		if me, err := expression.Parse(m.ID); err == nil {
+			fmt.Fprintf(os.Stderr, "resolved %q as %s (%s)\n", text, m.ID, m.Name)
+			return m.ID, me
+		}
+	}
+	return text, e
+}
+
+// knownTerms reports whether every license and exception of e is in ix or an alias.
+func knownTerms(e expression.Expr, ix idIndex, aliases map[string]string) bool {
+	known := func(ids []string, id string) bool {
+		_, listed := spdx.MatchFold(ids, id)
+		_, alias := aliases[id]
+		return listed || alias
+	}
+	for _, t := range expression.Terms(e) {
+		if !known(ix.licenses, t.License.ID) || (t.Exception != "" && !known(ix.exceptions, t.Exception)) {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	rootCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().BoolP("json", "j", false, "output as JSON")
+	resolveCmd.Flags().Int("limit", maxResolveMatches, "print at most this many matches (0 for all)")
+}
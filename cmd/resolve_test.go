@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+func TestResolveRunE(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-3.0-only"))
+
+	out, err := runCapture(t, resolveCmd, "Apache", "License", "2.0")
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if !strings.HasPrefix(out, "Apache-2.0  0.90  Apache License 2.0\n") {
+		t.Errorf("resolve Apache License 2.0 = %q", out)
+	}
+
+	setFlag(t, resolveCmd, "json", "true")
+	out, err = runCapture(t, resolveCmd, "https://opensource.org/licenses/MIT")
+	if err != nil {
+		t.Fatalf("RunE --json: %v", err)
+	}
+	var matches []spdx.Match
+	if err := json.Unmarshal([]byte(out), &matches); err != nil || len(matches) == 0 || matches[0].ID != "MIT" || matches[0].Confidence != 0.95 {
+		t.Errorf("resolve --json URL = %s, %v", out, err)
+	}
+
+	out, err = runCapture(t, resolveCmd, "zlib")
+	if !errors.Is(err, ErrNotFound) || out != "[]\n" {
+		t.Errorf("resolve zlib = %q, %v; want [] and ErrNotFound", out, err)
+	}
+}
+
+func TestGetRunE_ResolvesInformalName(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-3.0-only"))
+
+	r, w, _ := os.Pipe()
+	old := os.Stderr
+	os.Stderr = w
+	out, err := runCapture(t, getCmd, "GPLv3")
+	w.Close()
+	os.Stderr = old
+	if err != nil || out != "GPL-3.0-only text" {
+		t.Fatalf("get GPLv3 = %q, %v", out, err)
+	}
+	if stderr, _ := io.ReadAll(r); !strings.Contains(string(stderr), `resolved "GPLv3" as GPL-3.0-only`) {
+		t.Errorf("stderr = %q, want the resolution noted", stderr)
+	}
+
+	// A weak match is not used; it is only suggested.
+	if _, err := runCapture(t, getCmd, "Apache"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get Apache: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetRunE_InformalNameWithOrAndWith(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, newFixture("MIT", "GPL-2.0-only", "GPL-3.0-only", "GPL-3.0-or-later", "Classpath-exception-2.0"))
+
+	for _, tc := range []struct{ text, want string }{
+		{"GPLv3 or later", "GPL-3.0-or-later text"},
+		{"GPL-2.0 with classpath exception", "GPL-2.0-only text\n\nClasspath-exception-2.0 text"},
+	} {
+		var out string
+		var err error
+		stderr := captureStderr(t, func() { out, err = runCapture(t, getCmd, tc.text) })
+		if err != nil || out != tc.want {
+			t.Errorf("get %q = %q, %v; want %q", tc.text, out, err, tc.want)
+		}
+		if !strings.Contains(stderr, "resolved "+strconv.Quote(tc.text)) {
+			t.Errorf("get %q: stderr = %q, want the resolution noted", tc.text, stderr)
+		}
+	}
+	// An expression of listed IDs is still one.
+	if _, err := runCapture(t, getCmd, "MIT or GPL-2.0-only"); exitCodeFrom(err) != 1 {
+		t.Errorf("get MIT or GPL-2.0-only: expected usage error, got %v", err)
+	}
+}
+
+func TestWriteRunE_InformalNameWithOrAndWith(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "GPL-2.0-only", "GPL-3.0-only", "GPL-3.0-or-later", "Classpath-exception-2.0"))
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer func() { _ = os.Chdir(orig) }()
+
+	for _, tc := range []struct{ text, want string }{
+		{"GPLv3 or later", "GPL-3.0-or-later text"},
+		{"GPL-2.0 with classpath exception", "GPL-2.0-only text\n\nClasspath-exception-2.0 text"},
+	} {
+		path := filepath.Join(dir, "LICENSE")
+		_ = os.Remove(path)
+		captureStderr(t, func() {
+			if _, err := runCapture(t, writeCmd, tc.text); err != nil {
+				t.Errorf("write %q: %v", tc.text, err)
+			}
+		})
+		if b, err := os.ReadFile(path); err != nil || string(b) != tc.want {
+			t.Errorf("write %q: LICENSE = %q, %v; want %q", tc.text, b, err, tc.want)
+		}
+	}
+}
@@ -6,10 +6,27 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"runtime/debug"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// version is set by release builds with -ldflags "-X github.com/tom/ligma/cmd.version=1.2.3".
+var version string
+
+// appVersion returns the ligma version sent in the User-Agent of SPDX requests: version when set,
+// else the module version from the build info (go install), else "dev".
+func appVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return strings.TrimPrefix(info.Main.Version, "v")
+	}
+	return "dev"
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "ligma",
@@ -20,7 +37,7 @@ var rootCmd = &cobra.Command{
 	// Run: func(cmd *cobra.Command, args []string) { },
 }
 
-// Execute runs the root command and returns the exit code (0–3). main calls os.Exit(Execute()).
+// Execute runs the root command and returns the exit code (0–4). main calls os.Exit(Execute()).
 // All process exit is via os.Exit in main only; subcommands and internal/ must return errors.
 func Execute() int {
 	err := rootCmd.Execute()
@@ -37,6 +54,10 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.licensegen.yaml)")
+	rootCmd.PersistentFlags().StringVar(&listVersionFlag, "list-version", "", "pin the SPDX license list version, e.g. 3.24 (default: license_list_version in config, else latest)")
+	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "report cache write failures and corrupt cache entries on stderr")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "never use the network; serve SPDX data from the cache, then the embedded snapshot (also LIGMA_OFFLINE=1)")
+	rootCmd.PersistentFlags().StringVar(&sourceFlag, "source", "", "license data source: http, embedded (the SPDX data built into this binary: "+snapshotVersion()+") or dir:<path> (default: source in config, else http)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
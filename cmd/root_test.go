@@ -1,10 +1,8 @@
 package cmd
 
 import (
-	"context"
 	"testing"
 
-	"github.com/tom/ligma/internal/cache"
 	"github.com/tom/ligma/internal/config"
 	"github.com/tom/ligma/internal/spdx"
 )
@@ -59,11 +57,9 @@ func TestExecute_NotFound(t *testing.T) {
 	config.SetConfigDirOverride(t.TempDir())
 	defer config.SetConfigDirOverride("")
 
-	save := cache.FetchDetailsFn
-	cache.FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "", spdx.ErrNotFound
-	}
-	defer func() { cache.FetchDetailsFn = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return nil, spdx.ErrNotFound
+	}})
 
 	rootCmd.SetArgs([]string{"get", "x"})
 	defer rootCmd.SetArgs(nil)
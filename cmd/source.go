@@ -0,0 +1,438 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/cache"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/snapshot"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// sourceFlag is the global --source flag; when set it overrides the config "source" key.
+var sourceFlag string
+
+// listVersionFlag is the global --list-version flag; when set it overrides license_list_version.
+var listVersionFlag string
+
+// offlineFallback answers when both the cache and the network fail; nil disables the fallback.
+var offlineFallback spdx.Source = snapshot.Source()
+
+var offlineNotice, staleNotice sync.Once
+
+// offlineFlag is the global --offline flag; LIGMA_OFFLINE=1 has the same effect.
+var offlineFlag bool
+
+// verboseFlag is the global --verbose flag: report cache write failures and corrupt cache entries.
+var verboseFlag bool
+
+// verbosef prints a diagnostic line on stderr under --verbose.
+func verbosef(format string, args ...any) {
+	if verboseFlag {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// offline reports whether commands must stay off the network (--offline or LIGMA_OFFLINE).
+func offline() bool {
+	if offlineFlag {
+		return true
+	}
+	on, err := strconv.ParseBool(os.Getenv("LIGMA_OFFLINE"))
+	return err == nil && on
+}
+
+// sourceOverride, when non-nil, replaces the configured upstream source (for tests). It is still
+// wrapped by the file cache in cachedSource.
+var sourceOverride spdx.Source
+
+// sourceName returns the selected source: --source, else the config "source" key, else "http".
+func sourceName(cfg *config.Config) string {
+	if sourceFlag != "" {
+		return sourceFlag
+	}
+	if cfg.Source != "" {
+		return cfg.Source
+	}
+	return "http"
+}
+
+// listVersion returns the pinned SPDX license list version ("" = latest): --list-version, else the
+// config license_list_version key.
+func listVersion(cfg *config.Config) string {
+	if listVersionFlag != "" {
+		return listVersionFlag
+	}
+	return cfg.LicenseListVersion
+}
+
+// upstreamSource returns the license data backend selected by sourceName: "http" for the SPDX URLs
+// from config, "embedded" for the snapshot built into the binary, or "dir:<path>" for a local
+// license-list-data json/ directory. For "http", every URL is pinned to listVersion; the embedded
+// snapshot is refused when it is not the pinned version.
+func upstreamSource(cfg *config.Config) (spdx.Source, error) {
+	if sourceOverride != nil {
+		return sourceOverride, nil
+	}
+	name := sourceName(cfg)
+	switch {
+	case name == "http":
+		client, err := httpClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		src, err := (&spdx.HTTPSource{
+			ListURL:                     cfg.SPDXListURL,
+			DetailsURLTemplate:          cfg.SPDXGetURLTemplate,
+			ExceptionsURL:               cfg.SPDXExceptionsURL,
+			ExceptionDetailsURLTemplate: cfg.SPDXExceptionURLTemplate,
+			Client:                      client,
+
+			ListMirrors:                     cfg.SPDXListMirrors,
+			DetailsTemplateMirrors:          cfg.SPDXGetURLTemplateMirrors,
+			ExceptionsMirrors:               cfg.SPDXExceptionsMirrors,
+			ExceptionDetailsTemplateMirrors: cfg.SPDXExceptionTemplateMirrors,
+		}).Pinned(listVersion(cfg))
+		if err != nil {
+			return nil, err
+		}
+		return src, nil
+	case name == "embedded":
+		if v := listVersion(cfg); !snapshotMatches(v) {
+			sv, _ := snapshot.Version()
+			return nil, fmt.Errorf("the embedded SPDX license list is version %s, not the pinned %s", sv, v)
+		}
+		if snapshot.IsSeed() {
+			warnOnce("the embedded SPDX license list is %s; use --source=http or dir:<path> for the others", snapshotVersion())
+		}
+		return snapshot.Source(), nil
+	case strings.HasPrefix(name, "dir:") && len(name) > len("dir:"):
+		return spdx.NewDirSource(strings.TrimPrefix(name, "dir:")), nil
+	}
+	return nil, fmt.Errorf("invalid source %q: want \"http\", \"embedded\" or \"dir:<path>\"", name)
+}
+
+// httpClient returns the spdx client configured by http_timeout, http_retries, http_retry_max_wait and
+// the proxy, CA and client certificate keys; it identifies itself as ligma/<version>. Bearer tokens are
+// read from the environment variables named by http_auth_token_env (unset or empty: no token).
+// Mirror fallbacks and circuit breaker trips are reported on stderr.
+func httpClient(cfg *config.Config) (*spdx.Client, error) {
+	c := &spdx.Client{UserAgent: "ligma/" + appVersion(), Logf: func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}}
+	if cfg.HTTPProxy != "" || len(cfg.HTTPCAFiles) > 0 || cfg.HTTPClientCert != "" || cfg.HTTPClientKey != "" {
+		t, err := spdx.NewTransport(spdx.TransportOptions{
+			Proxy:    cfg.HTTPProxy,
+			CAFiles:  cfg.HTTPCAFiles,
+			CertFile: cfg.HTTPClientCert,
+			KeyFile:  cfg.HTTPClientKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.Transport = t
+	}
+	for host, env := range cfg.HTTPAuthTokenEnv {
+		if tok := os.Getenv(env); tok != "" {
+			if c.Tokens == nil {
+				c.Tokens = make(map[string]string)
+			}
+			c.Tokens[host] = tok
+		}
+	}
+	if cfg.HTTPTimeout != nil && *cfg.HTTPTimeout > 0 {
+		c.Timeout = time.Duration(*cfg.HTTPTimeout) * time.Second
+	}
+	if cfg.HTTPRetries != nil {
+		c.Retries = *cfg.HTTPRetries
+		if c.Retries == 0 {
+			c.Retries = -1 // config 0 = no retries; Client's zero value means the default
+		}
+	}
+	if cfg.HTTPRetryMaxWait != nil && *cfg.HTTPRetryMaxWait > 0 {
+		c.MaxDelay = time.Duration(*cfg.HTTPRetryMaxWait) * time.Second
+	}
+	return c, nil
+}
+
+// cachedSource returns the source for reading commands: the SPDX data of spdxSource, with the
+// organization's license registry (registrySource) merged in and the user's LicenseRef- licenses
+// (config.CustomLicenseDirs) added on top. The registry's aliases are resolved by the source, after
+// cfg.Aliases, and only for an ID it does not otherwise have.
+func cachedSource(cfg *config.Config) (spdx.Source, error) {
+	src, err := spdxSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := registrySource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if reg != nil {
+		src = &spdx.RegistrySource{Upstream: src, Registry: reg, Aliases: cfg.Aliases, Warnf: warnOnce}
+	}
+	dirs, err := config.CustomLicenseDirs()
+	if err != nil || len(dirs) == 0 {
+		return src, err
+	}
+	return &spdx.CustomSource{Upstream: src, Dirs: dirs, Warnf: warnOnce}, nil
+}
+
+// registrySource returns the organization license registry of org_registry_url, nil when none is
+// configured. A remote registry gets its own ~/.ligma/_cache directory under cache_ttl, and serves
+// expired entries with a warning when the network fails, like the SPDX data; a local one (a file: URL
+// or a path, with details/<id>.json next to the index) is read as is.
+func registrySource(cfg *config.Config) (spdx.Source, error) {
+	index := cfg.OrgRegistryURL
+	if index == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(index, "http://") && !strings.HasPrefix(index, "https://") {
+		if u, err := url.Parse(index); err == nil && u.Scheme == "file" {
+			index = u.Path
+		}
+		return &spdx.FSSource{FS: os.DirFS(filepath.Dir(index)), ListName: filepath.Base(index)}, nil
+	}
+	client, err := httpClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reg := &spdx.HTTPSource{
+		ListURL:                cfg.OrgRegistryURL,
+		DetailsURLTemplate:     cfg.OrgRegistryDetailsURLTemplate,
+		ListMirrors:            cfg.OrgRegistryMirrors,
+		DetailsTemplateMirrors: cfg.OrgRegistryDetailsTemplateMirrors,
+		Client:                 client,
+	}
+	if reg.DetailsURLTemplate == "" {
+		reg.DetailsURLTemplate = registryDetailsURLTemplate(reg.ListURL)
+		for _, m := range reg.ListMirrors {
+			reg.DetailsTemplateMirrors = append(reg.DetailsTemplateMirrors, registryDetailsURLTemplate(m))
+		}
+	}
+	dir, err := config.LigmaDir()
+	if err != nil {
+		return nil, err
+	}
+	c := cache.New(filepath.Join(dir, "_cache", cache.Namespace(sourceID(reg), "")), cache.TTL(cfg.CacheTTL), reg)
+	c.SourceID, c.Logf = sourceID(reg), verbosef
+	if offline() {
+		c.Offline = true
+		return c, nil
+	}
+	c.OnStale = func(err error) {
+		warnOnce("%v\nusing expired cached organization license registry data", err)
+	}
+	return c, nil
+}
+
+// registryDetailsURLTemplate is the default details URL template of the registry index at indexURL:
+// details/{id}.json next to it, as in license-list-data.
+func registryDetailsURLTemplate(indexURL string) string {
+	return indexURL[:strings.LastIndex(indexURL, "/")+1] + "details/{id}.json"
+}
+
+// warned holds the warnings warnOnce has printed.
+var warned sync.Map
+
+// warnOnce prints a warning on stderr the first time it is given, as lookups may repeat it.
+func warnOnce(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if _, dup := warned.LoadOrStore(msg, true); !dup {
+		fmt.Fprintln(os.Stderr, "warning: "+msg)
+	}
+}
+
+// spdxSource returns the SPDX data source: for "http", the ~/.ligma/_cache file cache (cache_ttl,
+// one directory per source and pinned list version) in front of the network, serving expired entries
+// with a warning when the network fails, with the embedded snapshot as the last fallback when it
+// matches the pinned version. In offline mode the network is never asked: the cache answers, then the
+// embedded snapshot, and anything in neither is cache.ErrNotCached. Local sources (embedded, dir:) are
+// returned as is; caching them would only hide edits to a directory.
+func spdxSource(cfg *config.Config) (spdx.Source, error) {
+	up, err := upstreamSource(cfg)
+	if err != nil || (sourceOverride == nil && sourceName(cfg) != "http") {
+		return up, err
+	}
+	c, err := fileCache(cfg, up)
+	if err != nil {
+		return nil, err
+	}
+	if offline() {
+		c.Offline = true
+		return withOfflineFallback(c, listVersion(cfg)), nil
+	}
+	c.OnStale = func(err error) {
+		staleNotice.Do(func() {
+			fmt.Fprintf(os.Stderr, "warning: %v\nusing expired cached SPDX data\n", err)
+		})
+	}
+	return withOfflineFallback(c, listVersion(cfg)), nil
+}
+
+// fileCache returns the ~/.ligma/_cache file cache in front of up (see cacheDir).
+func fileCache(cfg *config.Config, up spdx.Source) (*cache.Source, error) {
+	dir, err := cacheDir(cfg, up)
+	if err != nil {
+		return nil, err
+	}
+	c := cache.New(dir, cache.TTL(cfg.CacheTTL), up)
+	c.SourceID, c.ListVersion = sourceID(up), listVersionTag(cfg)
+	c.Logf = verbosef
+	return c, nil
+}
+
+// cacheDir returns the cache directory for data from up at the pinned list version:
+// ~/.ligma/_cache/<hash of both>, so that changing the SPDX URLs or the version starts a fresh cache.
+func cacheDir(cfg *config.Config, up spdx.Source) (string, error) {
+	dir, err := config.LigmaDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "_cache", cache.Namespace(sourceID(up), listVersionTag(cfg))), nil
+}
+
+// sourceID identifies up in the cache: the primary URLs of an HTTPSource (its mirrors serve the same
+// data), else its type.
+func sourceID(up spdx.Source) string {
+	if h, ok := up.(*spdx.HTTPSource); ok {
+		return strings.Join([]string{h.ListURL, h.DetailsURLTemplate, h.ExceptionsURL, h.ExceptionDetailsURLTemplate}, " ")
+	}
+	return fmt.Sprintf("%T", up)
+}
+
+// listVersionTag is listVersion as a release tag (e.g. "v3.24.0"); "" when not pinned.
+func listVersionTag(cfg *config.Config) string {
+	if v := listVersion(cfg); v != "" {
+		return spdx.ListVersionTag(v)
+	}
+	return ""
+}
+
+// withOfflineFallback puts offlineFallback behind src, with a one-time stderr notice naming the
+// snapshot version when it is used. A snapshot of another version than the pinned one never answers.
+func withOfflineFallback(src spdx.Source, version string) spdx.Source {
+	if offlineFallback == nil || !snapshotMatches(version) {
+		return src
+	}
+	return &spdx.FallbackSource{Primary: src, Fallback: offlineFallback, OnFallback: func(err error) {
+		offlineNotice.Do(func() {
+			fmt.Fprintf(os.Stderr, "warning: %v\nusing the embedded SPDX license list (%s)\n", err, snapshotVersion())
+		})
+	}}
+}
+
+// snapshotMatches reports whether the embedded snapshot can serve the pinned version ("" = any).
+func snapshotMatches(version string) bool {
+	if version == "" {
+		return true
+	}
+	sv, _ := snapshot.Version()
+	return spdx.SameListVersion(sv, version)
+}
+
+// snapshotVersion describes the embedded snapshot, e.g. "version 3.27 of 2025-07-01", or says that
+// it is only the seed.
+func snapshotVersion() string {
+	if snapshot.IsSeed() {
+		n := 0
+		if list, err := snapshot.Source().LicenseList(context.Background()); err == nil {
+			n = len(list.Licenses)
+		}
+		return fmt.Sprintf("a seed of %d common licenses, not the full SPDX list", n)
+	}
+	v, date := snapshot.Version()
+	if date == "" {
+		return "version " + v
+	}
+	return "version " + v + " of " + date
+}
+
+// commandContext returns cmd's context, or context.Background when RunE is called directly (tests).
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// lookupText fetches the license details for id from src, falling back to the SPDX exception of that
+// ID (as LicenseDetails, isException true) when no such license exists. An ID known only in another
+// case (mit) is fetched by its listed spelling (MIT), and informal text (GPLv3) by the license
+// spdx.Resolve confidently matches, noted on stderr. Errors are mapped to the command exit codes:
+// unknown ID → a notFoundError (ErrNotFound) suggesting close IDs from src and aliases, anything else →
+// ErrIOOrNetwork.
+func lookupText(ctx context.Context, src spdx.Source, aliases map[string]string, id string) (details *spdx.LicenseDetails, isException bool, err error) {
+	details, isException, err = fetchText(ctx, src, id)
+	if errors.Is(err, spdx.ErrNotFound) {
+		ix := loadIDIndex(ctx, src, aliases)
+		if canon, ok := spdx.MatchFold(append(ix.licenses, ix.exceptions...), id); ok && canon != id {
+			details, isException, err = fetchText(ctx, src, canon)
+		} else if m, ok := confidentMatch(id, ix.list); ok && m.ID != id && !strings.Contains(m.ID, " ") {
+			fmt.Fprintf(os.Stderr, "resolved %q as %s (%s)\n", id, m.ID, m.Name)
+			details, isException, err = fetchText(ctx, src, m.ID)
+		}
+		if errors.Is(err, spdx.ErrNotFound) {
+			return nil, false, notFound("license", id, ix.candidates)
+		}
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch license %s: %v: %w", id, err, ErrIOOrNetwork)
+	}
+	return details, isException, nil
+}
+
+// fetchText is lookupText for id as given, with src's errors.
+func fetchText(ctx context.Context, src spdx.Source, id string) (*spdx.LicenseDetails, bool, error) {
+	details, err := src.LicenseDetails(ctx, id)
+	if errors.Is(err, spdx.ErrNotFound) {
+		// Not a license; it may be an SPDX exception (exceptions live under a separate URL).
+		if ex, exErr := src.ExceptionDetails(ctx, id); exErr == nil {
+			return exceptionText(ex), true, nil
+		}
+	}
+	return details, false, err
+}
+
+// lookupException fetches the SPDX exception id from src as LicenseDetails, for the text appended to
+// the license it modifies. Case and errors are handled like lookupText's.
+func lookupException(ctx context.Context, src spdx.Source, aliases map[string]string, id string) (*spdx.LicenseDetails, error) {
+	ex, err := src.ExceptionDetails(ctx, id)
+	if errors.Is(err, spdx.ErrNotFound) {
+		ix := loadIDIndex(ctx, src, aliases)
+		if canon, ok := spdx.MatchFold(ix.exceptions, id); ok && canon != id {
+			ex, err = src.ExceptionDetails(ctx, canon)
+		}
+		if errors.Is(err, spdx.ErrNotFound) {
+			return nil, notFound("exception", id, ix.exceptionCandidates)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetch exception %s: %v: %w", id, err, ErrIOOrNetwork)
+	}
+	return exceptionText(ex), nil
+}
+
+// exceptionText presents ex as LicenseDetails, so that its text and template go through spdx.Fill.
+func exceptionText(ex *spdx.ExceptionDetails) *spdx.LicenseDetails {
+	return &spdx.LicenseDetails{
+		LicenseID:               ex.LicenseExceptionID,
+		Name:                    ex.Name,
+		LicenseText:             ex.LicenseExceptionText,
+		StandardLicenseTemplate: ex.LicenseExceptionTemplate,
+		IsDeprecatedLicenseID:   ex.IsDeprecatedLicenseID,
+	}
+}
@@ -0,0 +1,574 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tom/ligma/internal/cache"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/snapshot"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// funcSource is an spdx.Source whose methods call the given funcs; a nil func reports ErrNotFound.
+type funcSource struct {
+	list       func() (*spdx.LicenseList, error)
+	details    func(id string) (*spdx.LicenseDetails, error)
+	exceptions func() (*spdx.ExceptionList, error)
+	exception  func(id string) (*spdx.ExceptionDetails, error)
+}
+
+func (s *funcSource) LicenseList(ctx context.Context) (*spdx.LicenseList, error) {
+	if s.list == nil {
+		return nil, spdx.ErrNotFound
+	}
+	return s.list()
+}
+
+func (s *funcSource) LicenseDetails(ctx context.Context, id string) (*spdx.LicenseDetails, error) {
+	if s.details == nil {
+		return nil, spdx.ErrNotFound
+	}
+	return s.details(id)
+}
+
+func (s *funcSource) ExceptionList(ctx context.Context) (*spdx.ExceptionList, error) {
+	if s.exceptions == nil {
+		return nil, spdx.ErrNotFound
+	}
+	return s.exceptions()
+}
+
+func (s *funcSource) ExceptionDetails(ctx context.Context, id string) (*spdx.ExceptionDetails, error) {
+	if s.exception == nil {
+		return nil, spdx.ErrNotFound
+	}
+	return s.exception(id)
+}
+
+// stubSource makes src the upstream source for the rest of the test, with no snapshot fallback.
+func stubSource(t *testing.T, src spdx.Source) {
+	t.Helper()
+	save, saveFallback := sourceOverride, offlineFallback
+	sourceOverride, offlineFallback = src, nil
+	t.Cleanup(func() { sourceOverride, offlineFallback = save, saveFallback })
+}
+
+// fixtureLicenses and fixtureExceptions are what newFixture can list, with their names.
+var (
+	fixtureLicenses = map[string]spdx.License{
+		"MIT":              {LicenseID: "MIT", Name: "MIT License", SeeAlso: []string{"https://opensource.org/licenses/MIT"}},
+		"ISC":              {LicenseID: "ISC", Name: "ISC License"},
+		"0BSD":             {LicenseID: "0BSD", Name: "BSD Zero Clause License"},
+		"Apache-2.0":       {LicenseID: "Apache-2.0", Name: "Apache License 2.0"},
+		"GPL-2.0-only":     {LicenseID: "GPL-2.0-only", Name: "GNU General Public License v2.0 only"},
+		"GPL-3.0-only":     {LicenseID: "GPL-3.0-only", Name: "GNU General Public License v3.0 only"},
+		"GPL-3.0-or-later": {LicenseID: "GPL-3.0-or-later", Name: "GNU General Public License v3.0 or later"},
+	}
+	fixtureExceptions = map[string]spdx.Exception{
+		"LLVM-exception":          {LicenseExceptionID: "LLVM-exception", Name: "LLVM Exception"},
+		"Classpath-exception-2.0": {LicenseExceptionID: "Classpath-exception-2.0", Name: "Classpath exception 2.0"},
+	}
+)
+
+// fixture is the stub source of the cmd tests, made by newFixture.
+type fixture struct {
+	spdx.MemorySource
+	// fail makes the details of these IDs fail like a broken network.
+	fail map[string]bool
+
+	mu    sync.Mutex
+	calls int // license details fetches
+}
+
+// newFixture lists the given licenses and exceptions of fixtureLicenses and fixtureExceptions and
+// serves their details, with the text "<id> text", by exact ID only.
+func newFixture(ids ...string) *fixture {
+	f := &fixture{MemorySource: spdx.MemorySource{
+		Licenses:             &spdx.LicenseList{},
+		LicenseDetailsByID:   make(map[string]*spdx.LicenseDetails),
+		Exceptions:           &spdx.ExceptionList{},
+		ExceptionDetailsByID: make(map[string]*spdx.ExceptionDetails),
+	}}
+	for _, id := range ids {
+		if e, ok := fixtureExceptions[id]; ok {
+			f.Exceptions.Exceptions = append(f.Exceptions.Exceptions, e)
+			f.ExceptionDetailsByID[id] = &spdx.ExceptionDetails{LicenseExceptionID: id, Name: e.Name, LicenseExceptionText: id + " text"}
+			continue
+		}
+		l, ok := fixtureLicenses[id]
+		if !ok {
+			panic("newFixture: unknown ID " + id)
+		}
+		f.Licenses.Licenses = append(f.Licenses.Licenses, l)
+		f.LicenseDetailsByID[id] = &spdx.LicenseDetails{LicenseID: id, Name: l.Name, LicenseText: id + " text"}
+	}
+	return f
+}
+
+// LicenseDetails counts the fetch and fails it for an ID in f.fail.
+func (f *fixture) LicenseDetails(ctx context.Context, id string) (*spdx.LicenseDetails, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	if f.fail[id] {
+		return nil, errors.New("connection reset")
+	}
+	return f.MemorySource.LicenseDetails(ctx, id)
+}
+
+func TestUpstreamSource_FromConfig(t *testing.T) {
+	if src, err := upstreamSource(&config.Config{}); err != nil {
+		t.Errorf("default source: %v", err)
+	} else if _, ok := src.(*spdx.HTTPSource); !ok {
+		t.Errorf("default source = %T, want *spdx.HTTPSource", src)
+	}
+	if src, err := upstreamSource(&config.Config{Source: "dir:/tmp/json"}); err != nil {
+		t.Errorf("dir source: %v", err)
+	} else if _, ok := src.(*spdx.FSSource); !ok {
+		t.Errorf("dir source = %T, want *spdx.FSSource", src)
+	}
+	if src, err := upstreamSource(&config.Config{Source: "embedded"}); err != nil {
+		t.Errorf("embedded source: %v", err)
+	} else if _, ok := src.(*spdx.FSSource); !ok {
+		t.Errorf("embedded source = %T, want *spdx.FSSource", src)
+	}
+	sourceFlag = "embedded"
+	if src, err := upstreamSource(&config.Config{Source: "ftp"}); err != nil {
+		t.Errorf("--source overriding config: %v", err)
+	} else if _, ok := src.(*spdx.FSSource); !ok {
+		t.Errorf("--source=embedded = %T, want *spdx.FSSource", src)
+	}
+	sourceFlag = ""
+	if _, err := upstreamSource(&config.Config{Source: "embedded", LicenseListVersion: "2.0"}); err == nil {
+		t.Error("embedded source pinned to another version: expected error")
+	}
+	if _, err := upstreamSource(&config.Config{SPDXListURL: "https://mirror.example/licenses.json", LicenseListVersion: "3.24"}); err == nil {
+		t.Error("pinned version with an unversioned list URL: expected error")
+	}
+	for _, bad := range []string{"ftp", "dir:"} {
+		if _, err := upstreamSource(&config.Config{Source: bad}); err == nil {
+			t.Errorf("source %q: expected error", bad)
+		}
+	}
+}
+
+func TestUpstreamSource_EmbeddedSeedWarns(t *testing.T) {
+	if !snapshot.IsSeed() {
+		t.Skip("the embedded snapshot is a full SPDX list")
+	}
+	warned.Clear()
+	defer warned.Clear()
+	r, w, _ := os.Pipe()
+	old := os.Stderr
+	os.Stderr = w
+	for range 2 {
+		if _, err := upstreamSource(&config.Config{Source: "embedded"}); err != nil {
+			t.Errorf("embedded source: %v", err)
+		}
+	}
+	w.Close()
+	os.Stderr = old
+	stderr, _ := io.ReadAll(r)
+	if want := "warning: the embedded SPDX license list is a seed of 16 common licenses, not the full SPDX list"; strings.Count(string(stderr), want) != 1 {
+		t.Errorf("stderr = %q, want %q once", stderr, want)
+	}
+}
+
+func TestCachedSource_WrapsUpstream(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &spdx.MemorySource{})
+
+	src, err := cachedSource(&config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := src.(*cache.Source); !ok {
+		t.Errorf("cachedSource = %T, want *cache.Source", src)
+	}
+}
+
+func TestGetRunE_DirSource(t *testing.T) {
+	data := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(data, "details"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "details", "MIT.json"), []byte(`{"licenseId":"MIT","licenseText":"from dir"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"source":"dir:`+filepath.ToSlash(data)+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+
+	if err := getCmd.RunE(getCmd, []string{"MIT"}); err != nil {
+		t.Errorf("RunE: %v", err)
+	}
+	if err := getCmd.RunE(getCmd, []string{"Nope"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RunE(Nope): expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetRunE_FallsBackToSnapshot(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, &spdx.MemorySource{Err: errors.New("connection refused")})
+	offlineFallback = &spdx.MemorySource{LicenseDetailsByID: map[string]*spdx.LicenseDetails{
+		"MIT": {LicenseID: "MIT", LicenseText: "from snapshot"},
+	}}
+
+	if err := getCmd.RunE(getCmd, []string{"MIT"}); err != nil {
+		t.Errorf("RunE: %v", err)
+	}
+	// not in the snapshot either: the network error is reported, not a 404
+	if err := getCmd.RunE(getCmd, []string{"ISC"}); !errors.Is(err, ErrIOOrNetwork) {
+		t.Errorf("RunE(ISC): expected ErrIOOrNetwork, got %v", err)
+	}
+}
+
+func TestHTTPClient_FromConfig(t *testing.T) {
+	c, err := httpClient(&config.Config{})
+	if err != nil || c.Timeout != 0 || c.Retries != 0 || c.MaxDelay != 0 || c.Transport != nil {
+		t.Errorf("default client = %+v, %v, want spdx defaults", c, err)
+	}
+	if !strings.HasPrefix(c.UserAgent, "ligma/") {
+		t.Errorf("UserAgent = %q", c.UserAgent)
+	}
+	timeout, retries, wait := 5, 0, 60
+	c, _ = httpClient(&config.Config{HTTPTimeout: &timeout, HTTPRetries: &retries, HTTPRetryMaxWait: &wait})
+	if c.Timeout != 5*time.Second || c.Retries != -1 || c.MaxDelay != time.Minute {
+		t.Errorf("client = %+v", c)
+	}
+	retries = 4
+	if c, _ := httpClient(&config.Config{HTTPRetries: &retries}); c.Retries != 4 {
+		t.Errorf("Retries = %d, want 4", c.Retries)
+	}
+}
+
+func TestHTTPClient_TransportAndTokens(t *testing.T) {
+	t.Setenv("LIGMA_TEST_TOKEN", "s3cret")
+	c, err := httpClient(&config.Config{
+		HTTPProxy:        "http://proxy.corp:3128",
+		HTTPAuthTokenEnv: map[string]string{"mirror.corp": "LIGMA_TEST_TOKEN", "other.corp": "LIGMA_TEST_UNSET"},
+	})
+	if err != nil {
+		t.Fatalf("httpClient: %v", err)
+	}
+	if c.Transport == nil {
+		t.Error("Transport not set for http_proxy")
+	}
+	if len(c.Tokens) != 1 || c.Tokens["mirror.corp"] != "s3cret" {
+		t.Errorf("Tokens = %v, want only mirror.corp", c.Tokens)
+	}
+
+	// A bad transport setting fails the command instead of silently going direct.
+	if _, err := upstreamSource(&config.Config{HTTPProxy: "::"}); err == nil {
+		t.Error("upstreamSource with invalid http_proxy: expected error")
+	}
+}
+
+// cacheEntry writes body as the expired cache entry rel in the cache directory of the stubbed source.
+func cacheEntry(t *testing.T, rel, body string) {
+	t.Helper()
+	dir, err := cacheDir(&config.Config{}, sourceOverride)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, rel)
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	_ = os.Chtimes(path, old, old)
+}
+
+func TestGetRunE_ServesExpiredCacheOnError(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, &spdx.MemorySource{Err: errors.New("connection refused")})
+	cacheEntry(t, "details/MIT.json", `{"licenseId":"MIT","licenseText":"cached text"}`)
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := getCmd.RunE(getCmd, []string{"MIT"})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if out, _ := io.ReadAll(r); string(out) != "cached text" {
+		t.Errorf("output = %q, want the expired cache entry", out)
+	}
+}
+
+func TestOffline_CacheOnly(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		t.Errorf("network fetch of %s in offline mode", id)
+		return nil, errors.New("unexpected")
+	}})
+	// Offline mode falls back to the snapshot; what neither holds is reported as not cached.
+	offlineFallback = &spdx.MemorySource{LicenseDetailsByID: map[string]*spdx.LicenseDetails{
+		"ISC": {LicenseID: "ISC", LicenseText: "from snapshot"},
+	}}
+	cacheEntry(t, "details/MIT.json", `{"licenseId":"MIT","licenseText":"cached text"}`)
+	t.Setenv("LIGMA_OFFLINE", "1")
+
+	if err := getCmd.RunE(getCmd, []string{"MIT"}); err != nil {
+		t.Errorf("RunE(MIT): %v", err)
+	}
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := getCmd.RunE(getCmd, []string{"ISC"})
+	w.Close()
+	os.Stdout = old
+	if out, _ := io.ReadAll(r); err != nil || string(out) != "from snapshot" {
+		t.Errorf("RunE(ISC) = %q, %v; want the snapshot text", out, err)
+	}
+	err = getCmd.RunE(getCmd, []string{"Zlib"})
+	if !errors.Is(err, ErrIOOrNetwork) || !strings.Contains(err.Error(), "ligma sync") {
+		t.Errorf("RunE(Zlib): expected an ErrIOOrNetwork naming ligma sync, got %v", err)
+	}
+	if err := writeCmd.RunE(writeCmd, []string{"MIT", filepath.Join(dir, "LICENSE")}); err != nil {
+		t.Errorf("write offline: %v", err)
+	}
+
+	t.Setenv("LIGMA_OFFLINE", "")
+	offlineFlag = true
+	defer func() { offlineFlag = false }()
+	if err := lsCmd.RunE(lsCmd, []string{}); exitCodeFrom(err) != 3 {
+		t.Errorf("ls --offline with nothing cached: expected exit 3, got %v", err)
+	}
+	if err := syncCmd.RunE(syncCmd, []string{}); exitCodeFrom(err) != 1 {
+		t.Errorf("sync --offline: expected usage error, got %v", err)
+	}
+}
+
+func TestGetRunE_CorruptCacheEntryVerbose(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, &spdx.MemorySource{LicenseDetailsByID: map[string]*spdx.LicenseDetails{
+		"MIT": {LicenseID: "MIT", LicenseText: "fetched"},
+	}})
+	cacheEntry(t, "details/MIT.json", `{"licenseId":"MIT","licenseTe`)
+	dir, _ := cacheDir(&config.Config{}, sourceOverride)
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(dir, "details", "MIT.json"), now, now) // fresh but truncated
+	verboseFlag = true
+	defer func() { verboseFlag = false }()
+
+	r, w, _ := os.Pipe()
+	old := os.Stderr
+	os.Stderr = w
+	_, err := runCapture(t, getCmd, "MIT")
+	w.Close()
+	os.Stderr = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if stderr, _ := io.ReadAll(r); !strings.Contains(string(stderr), "ignoring unreadable entry") {
+		t.Errorf("stderr = %q, want the corrupt entry reported", stderr)
+	}
+}
+
+func TestCachedSource_CustomLicenses(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+	user := filepath.Join(dir, "licenses")
+	_ = os.MkdirAll(user, 0755)
+	_ = os.WriteFile(filepath.Join(user, "LicenseRef-Acme.json"), []byte(`{"name":"Acme License","licenseText":"Copyright <year> <copyright holders>\n"}`), 0644)
+	_ = os.WriteFile(filepath.Join(user, "LicenseRef-Shared.txt"), []byte("user text\n"), 0644)
+	// The project's licenses win over the user's.
+	project := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(project, ".ligma", "licenses"), 0755)
+	_ = os.WriteFile(filepath.Join(project, ".ligma", "licenses", "LicenseRef-Shared.txt"), []byte("project text\n"), 0644)
+	orig, _ := os.Getwd()
+	_ = os.Chdir(project)
+	defer func() { _ = os.Chdir(orig) }()
+
+	out, err := runCapture(t, lsCmd)
+	if err != nil || !strings.Contains(out, "MIT\n") || !strings.Contains(out, "LicenseRef-Acme (custom)\n") {
+		t.Errorf("ls = %q, %v; want MIT and LicenseRef-Acme marked custom", out, err)
+	}
+	setFlag(t, getCmd, "holder", "Acme Corp")
+	setFlag(t, getCmd, "year", "2026")
+	if out, err := runCapture(t, getCmd, "LicenseRef-Acme"); err != nil || out != "Copyright 2026 Acme Corp\n" {
+		t.Errorf("get LicenseRef-Acme = %q, %v", out, err)
+	}
+	if out, err := runCapture(t, getCmd, "LicenseRef-Shared"); err != nil || out != "project text\n" {
+		t.Errorf("get LicenseRef-Shared = %q, %v; want the project's text", out, err)
+	}
+	if _, err := runCapture(t, validateCmd, "MIT OR LicenseRef-Acme"); err != nil {
+		t.Errorf("validate: %v", err)
+	}
+	if _, err := runCapture(t, writeCmd, "MIT OR LicenseRef-Acme"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(project, "LICENSE-ACME")); err != nil || !strings.HasPrefix(string(b), "Copyright ") {
+		t.Errorf("LICENSE-ACME = %q, %v", b, err)
+	}
+	if _, err := runCapture(t, getCmd, "LicenseRef-Missing"); exitCodeFrom(err) != 2 {
+		t.Errorf("get LicenseRef-Missing: expected exit 2, got %v", err)
+	}
+}
+
+func TestCachedSource_OrgRegistry(t *testing.T) {
+	var hits, listHits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/reg/licenses.json":
+			listHits++
+			_, _ = w.Write([]byte(`{"licenses":[{"licenseId":"LicenseRef-Acme","name":"Acme License"},{"licenseId":"MIT","name":"Acme MIT"}],
+				"aliases":{"acme":"LicenseRef-Acme","corp-default":"Apache-2.0","MIT":"LicenseRef-Acme"}}`))
+		case "/reg/details/LicenseRef-Acme.json":
+			_, _ = w.Write([]byte(`{"licenseId":"LicenseRef-Acme","licenseText":"Acme text"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	cfgJSON := `{"org_registry_url":"` + srv.URL + `/reg/licenses.json","aliases":{"corp-default":"MIT"},"http_retries":0}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(cfgJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+	warned.Clear()
+	defer warned.Clear()
+
+	// An ID the SPDX data has does not need the registry's list.
+	if out, err := runCapture(t, getCmd, "MIT"); err != nil || out != "MIT text" || listHits != 0 {
+		t.Errorf("get MIT = %q, %v, %d registry list requests; want the SPDX MIT alone", out, err, listHits)
+	}
+
+	var out string
+	var err error
+	stderr := captureStderr(t, func() { out, err = runCapture(t, lsCmd) })
+	if err != nil || !strings.Contains(out, "LicenseRef-Acme (org)\n") || strings.Contains(out, "MIT (org)") {
+		t.Errorf("ls = %q, %v; want LicenseRef-Acme marked org and the SPDX MIT alone", out, err)
+	}
+	for _, want := range []string{
+		"MIT in the organization license registry is already listed",
+		`alias "corp-default" is MIT in your aliases and Apache-2.0`,
+		`alias "MIT" in the organization license registry would replace the license MIT`,
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr = %q, want %q", stderr, want)
+		}
+	}
+
+	stderr = captureStderr(t, func() { out, err = runCapture(t, getCmd, "acme") })
+	if err != nil || out != "Acme text" {
+		t.Errorf("get acme = %q, %v", out, err)
+	}
+	if strings.Contains(stderr, "warning") {
+		t.Errorf("warnings repeated: %q", stderr)
+	}
+	if out, err := runCapture(t, getCmd, "MIT"); err != nil || out != "MIT text" {
+		t.Errorf("get MIT = %q, %v; want the SPDX MIT, not the registry alias", out, err)
+	}
+	if out, err := runCapture(t, getCmd, "corp-default"); err != nil || out != "MIT text" {
+		t.Errorf("get corp-default = %q, %v; want the config.json alias", out, err)
+	}
+
+	// The registry is cached under cache_ttl like the SPDX data.
+	before := hits
+	if out, err := runCapture(t, getCmd, "LicenseRef-Acme"); err != nil || out != "Acme text" || hits != before {
+		t.Errorf("cached get = %q, %v, %d more requests", out, err, hits-before)
+	}
+}
+
+func TestCachedSource_LocalOrgRegistry(t *testing.T) {
+	reg := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(reg, "details"), 0755)
+	_ = os.WriteFile(filepath.Join(reg, "index.json"), []byte(`{"licenses":[{"licenseId":"LicenseRef-Acme"}]}`), 0644)
+	_ = os.WriteFile(filepath.Join(reg, "details", "LicenseRef-Acme.json"), []byte(`{"licenseId":"LicenseRef-Acme","licenseText":"Acme text"}`), 0644)
+	dir := t.TempDir()
+	cfgJSON := `{"org_registry_url":"file://` + filepath.ToSlash(filepath.Join(reg, "index.json")) + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(cfgJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+
+	if out, err := runCapture(t, getCmd, "LicenseRef-Acme"); err != nil || out != "Acme text" {
+		t.Errorf("get = %q, %v", out, err)
+	}
+	if _, err := runCapture(t, validateCmd, "MIT AND LicenseRef-Acme"); err != nil {
+		t.Errorf("validate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_cache")); err == nil {
+		entries, _ := cache.Entries(filepath.Join(dir, "_cache"))
+		for _, e := range entries {
+			if strings.Contains(e.Path, "LicenseRef-Acme") {
+				t.Errorf("a local registry was cached: %s", e.Path)
+			}
+		}
+	}
+}
+
+func TestCachedSource_OrgRegistryAndCustomDir(t *testing.T) {
+	reg := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(reg, "details"), 0755)
+	_ = os.WriteFile(filepath.Join(reg, "licenses.json"), []byte(`{"licenses":[{"licenseId":"LicenseRef-Acme-EULA"},{"licenseId":"LicenseRef-Shared"}]}`), 0644)
+	_ = os.WriteFile(filepath.Join(reg, "details", "LicenseRef-Acme-EULA.json"), []byte(`{"licenseId":"LicenseRef-Acme-EULA","licenseText":"EULA text"}`), 0644)
+	_ = os.WriteFile(filepath.Join(reg, "details", "LicenseRef-Shared.json"), []byte(`{"licenseId":"LicenseRef-Shared","licenseText":"org text"}`), 0644)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"org_registry_url":"`+filepath.ToSlash(filepath.Join(reg, "licenses.json"))+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A custom licenses dir that does not have the registry's EULA.
+	_ = os.MkdirAll(filepath.Join(dir, "licenses"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "licenses", "LicenseRef-Shared.txt"), []byte("custom text"), 0644)
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+	warned.Clear()
+	defer warned.Clear()
+
+	var out string
+	var err error
+	captureStderr(t, func() { out, err = runCapture(t, lsCmd) })
+	if err != nil || !strings.Contains(out, "LicenseRef-Acme-EULA (org)\n") || !strings.Contains(out, "LicenseRef-Shared (custom)\n") {
+		t.Errorf("ls = %q, %v", out, err)
+	}
+	if out, err := runCapture(t, getCmd, "LicenseRef-Acme-EULA"); err != nil || out != "EULA text" {
+		t.Errorf("get of the registry's license = %q, %v", out, err)
+	}
+	if out, err := runCapture(t, getCmd, "LicenseRef-Shared"); err != nil || out != "custom text" {
+		t.Errorf("get of the custom license = %q, %v; want the custom text", out, err)
+	}
+}
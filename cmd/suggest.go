@@ -0,0 +1,85 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// maxSuggestions is how many close IDs a not-found error names.
+const maxSuggestions = 3
+
+// notFoundError is an unknown license or exception ID, with the known IDs closest to it.
+type notFoundError struct {
+	Kind        string   `json:"kind"` // "license" or "exception"
+	ID          string   `json:"id"`
+	Suggestions []string `json:"suggestions"`
+}
+
+func (e *notFoundError) Error() string {
+	msg := fmt.Sprintf("%s not found: %s", e.Kind, e.ID)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+func (e *notFoundError) Unwrap() error { return ErrNotFound }
+
+// idIndex is what a source lists, for resolving an ID it does not know verbatim.
+type idIndex struct {
+	list                 []spdx.License
+	licenses, exceptions []string
+	// candidates are all licenses and exceptions with their names and aliases; exceptionCandidates
+	// the exceptions alone.
+	candidates, exceptionCandidates []spdx.Candidate
+}
+
+// loadIDIndex reads the license and exception lists of src. A list that cannot be read is left out:
+// the index only improves an error that is reported either way.
+func loadIDIndex(ctx context.Context, src spdx.Source, aliases map[string]string) idIndex {
+	var ix idIndex
+	byID := make(map[string]int)
+	add := func(id, name string) {
+		byID[id] = len(ix.candidates)
+		ix.candidates = append(ix.candidates, spdx.Candidate{ID: id, Name: name})
+	}
+	var listAliases map[string]string
+	if list, err := src.LicenseList(ctx); err == nil {
+		ix.list = list.Licenses
+		for _, l := range list.Licenses {
+			ix.licenses = append(ix.licenses, l.LicenseID)
+			add(l.LicenseID, l.Name)
+		}
+		listAliases = list.Aliases // an organization registry's
+	}
+	if list, err := src.ExceptionList(ctx); err == nil {
+		for _, e := range list.Exceptions {
+			ix.exceptions = append(ix.exceptions, e.LicenseExceptionID)
+			add(e.LicenseExceptionID, e.Name)
+			ix.exceptionCandidates = append(ix.exceptionCandidates, ix.candidates[len(ix.candidates)-1])
+		}
+	}
+	// An alias suggests the ID it stands for.
+	for _, m := range []map[string]string{aliases, listAliases} {
+		for alias, id := range m {
+			if i, ok := byID[id]; ok {
+				ix.candidates[i].Aliases = append(ix.candidates[i].Aliases, alias)
+			} else {
+				ix.candidates = append(ix.candidates, spdx.Candidate{ID: id, Aliases: []string{alias}})
+				byID[id] = len(ix.candidates) - 1
+			}
+		}
+	}
+	return ix
+}
+
+// notFound returns the error for an unknown id of kind, suggesting the closest of candidates.
+func notFound(kind, id string, candidates []spdx.Candidate) error {
+	return &notFoundError{Kind: kind, ID: id, Suggestions: spdx.Suggest(id, candidates, maxSuggestions)}
+}
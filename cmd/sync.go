@@ -0,0 +1,200 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/cache"
+	"github.com/tom/ligma/internal/config"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Download every SPDX license and exception into the cache",
+	Long: `Fetch the SPDX license and exception lists, then the details of every license and exception, into the
+~/.ligma/_cache file cache so that later commands work offline. Entries still within cache_ttl are skipped.
+Prints a summary of fetched, skipped and failed IDs; exit code 3 when any fetch failed.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().IntP("concurrency", "c", 8, "number of parallel downloads")
+}
+
+// syncJob is one details entry to fetch.
+type syncJob struct {
+	id        string
+	exception bool
+}
+
+// syncResult is the outcome of a syncJob; skipped means the cached entry was still fresh.
+type syncResult struct {
+	syncJob
+	skipped bool
+	err     error
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	workers, _ := cmd.Flags().GetInt("concurrency")
+	if workers < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if offline() {
+		return fmt.Errorf("sync needs the network; unset --offline / LIGMA_OFFLINE")
+	}
+	if sourceOverride == nil && sourceName(cfg) != "http" {
+		return fmt.Errorf("sync only applies to the http source, not %q", sourceName(cfg))
+	}
+	up, err := upstreamSource(cfg)
+	if err != nil {
+		return err
+	}
+	c, err := fileCache(cfg, up)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt)
+	defer stop()
+
+	list, err := c.LicenseList(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed to fetch license list: %v", ErrIOOrNetwork, err)
+	}
+	exList, err := c.ExceptionList(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed to fetch exception list: %v", ErrIOOrNetwork, err)
+	}
+	jobs := make([]syncJob, 0, len(list.Licenses)+len(exList.Exceptions))
+	for _, l := range list.Licenses {
+		jobs = append(jobs, syncJob{id: l.LicenseID})
+	}
+	for _, e := range exList.Exceptions {
+		jobs = append(jobs, syncJob{id: e.LicenseExceptionID, exception: true})
+	}
+
+	progress := newSyncProgress(len(jobs))
+	var fetched, skipped int
+	var failed []syncResult
+	for r := range syncAll(ctx, c, jobs, workers) {
+		switch {
+		case r.err != nil && ctx.Err() != nil && errors.Is(r.err, context.Canceled):
+			continue // cut short by Ctrl-C: counted as not attempted
+		case r.err != nil:
+			failed = append(failed, r)
+		case r.skipped:
+			skipped++
+		default:
+			fetched++
+		}
+		progress.step()
+	}
+	progress.done()
+
+	sort.Slice(failed, func(i, j int) bool { return failed[i].id < failed[j].id })
+	for _, r := range failed {
+		fmt.Fprintf(os.Stderr, "failed: %s: %v\n", r.id, r.err)
+	}
+	fmt.Printf("%d fetched, %d skipped (fresh), %d failed", fetched, skipped, len(failed))
+	if n := len(jobs) - fetched - skipped - len(failed); n > 0 {
+		fmt.Printf(", %d not attempted", n)
+	}
+	fmt.Println()
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("sync interrupted")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: sync failed for %d of %d IDs", ErrIOOrNetwork, len(failed), len(jobs))
+	}
+	return nil
+}
+
+// syncAll fetches jobs into c with the given number of workers and streams the results. Fresh entries
+// are reported as skipped without a fetch. After ctx is canceled no new job is started.
+func syncAll(ctx context.Context, c *cache.Source, jobs []syncJob, workers int) <-chan syncResult {
+	queue := make(chan syncJob)
+	results := make(chan syncResult)
+	go func() {
+		defer close(queue)
+		for _, j := range jobs {
+			select {
+			case queue <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for range min(workers, max(len(jobs), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				results <- syncOne(ctx, c, j)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func syncOne(ctx context.Context, c *cache.Source, j syncJob) syncResult {
+	if j.exception {
+		if c.ExceptionDetailsFresh(j.id) {
+			return syncResult{syncJob: j, skipped: true}
+		}
+		_, err := c.ExceptionDetails(ctx, j.id)
+		return syncResult{syncJob: j, err: err}
+	}
+	if c.LicenseDetailsFresh(j.id) {
+		return syncResult{syncJob: j, skipped: true}
+	}
+	_, err := c.LicenseDetails(ctx, j.id)
+	return syncResult{syncJob: j, err: err}
+}
+
+// syncProgress shows an "n/total" counter on stderr, redrawn in place, when stderr is a terminal.
+type syncProgress struct {
+	n, total int
+	tty      bool
+}
+
+func newSyncProgress(total int) *syncProgress {
+	fi, err := os.Stderr.Stat()
+	return &syncProgress{total: total, tty: err == nil && fi.Mode()&os.ModeCharDevice != 0}
+}
+
+func (p *syncProgress) step() {
+	p.n++
+	if p.tty {
+		fmt.Fprintf(os.Stderr, "\rsyncing %d/%d", p.n, p.total)
+	}
+}
+
+func (p *syncProgress) done() {
+	if p.tty && p.n > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tom/ligma/internal/config"
+)
+
+func TestSyncRunE_FetchesThenSkipsFresh(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	src := newFixture("MIT", "ISC", "0BSD", "LLVM-exception")
+	stubSource(t, src)
+
+	out, err := runCapture(t, syncCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if out != "4 fetched, 0 skipped (fresh), 0 failed\n" || src.calls != 3 {
+		t.Errorf("first sync: out = %q, details calls = %d", out, src.calls)
+	}
+	cached, _ := cacheDir(&config.Config{}, src)
+	if _, err := os.Stat(filepath.Join(cached, "exceptions", "LLVM-exception.json")); err != nil {
+		t.Errorf("exception details not cached: %v", err)
+	}
+
+	out, err = runCapture(t, syncCmd)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if out != "0 fetched, 4 skipped (fresh), 0 failed\n" || src.calls != 3 {
+		t.Errorf("second sync: out = %q, details calls = %d", out, src.calls)
+	}
+}
+
+func TestSyncRunE_PartialFailure(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	src := newFixture("MIT", "ISC", "0BSD", "LLVM-exception")
+	src.fail = map[string]bool{"ISC": true}
+	stubSource(t, src)
+
+	_ = syncCmd.Flags().Set("concurrency", "1")
+	defer func() { _ = syncCmd.Flags().Set("concurrency", "8") }()
+
+	out, err := runCapture(t, syncCmd)
+	if !errors.Is(err, ErrIOOrNetwork) {
+		t.Errorf("RunE: expected ErrIOOrNetwork, got %v", err)
+	}
+	if out != "3 fetched, 0 skipped (fresh), 1 failed\n" {
+		t.Errorf("out = %q", out)
+	}
+}
+
+func TestSyncRunE_Interrupted(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "ISC", "0BSD", "LLVM-exception"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	syncCmd.SetContext(ctx)
+	defer syncCmd.SetContext(context.Background())
+
+	_, err := runCapture(t, syncCmd)
+	if err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("RunE: expected an interrupted error (exit 1), got %v", err)
+	}
+}
+
+func TestSyncRunE_Usage(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	_ = syncCmd.Flags().Set("concurrency", "0")
+	err := syncCmd.RunE(syncCmd, []string{})
+	_ = syncCmd.Flags().Set("concurrency", "8")
+	if err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("--concurrency 0: expected usage error, got %v", err)
+	}
+
+	sourceFlag = "embedded"
+	defer func() { sourceFlag = "" }()
+	if err := syncCmd.RunE(syncCmd, []string{}); err == nil || exitCodeFrom(err) != 1 {
+		t.Errorf("--source embedded: expected usage error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+	"github.com/tom/ligma/internal/spdx/expression"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <expression>",
+	Short: "Validate an SPDX license expression",
+	Long: `Parse an SPDX license expression (AND, OR, WITH, "+", parentheses, LicenseRef-/DocumentRef-) and check
+every license and exception ID against the SPDX lists, in any case. A LicenseRef- that is not a custom or
+organization registry license prints a warning (an error with --strict). Prints the expression in canonical
+form, with the IDs as listed, on success.
+Exit code 1 for a malformed expression, 2 when an ID is unknown.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runValidate,
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	input := args[0]
+	expr, err := expression.Parse(input)
+	if err != nil {
+		var se *expression.SyntaxError
+		if errors.As(err, &se) {
+			return fmt.Errorf("invalid expression: %v\n%s", se, expression.Caret(input, se.Pos))
+		}
+		return fmt.Errorf("invalid expression: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	src, err := cachedSource(cfg)
+	if err != nil {
+		return err
+	}
+	licenses, exceptions, err := knownIDs(commandContext(cmd), src)
+	if err != nil {
+		return err
+	}
+
+	if err := expression.Validate(expr, licenses, exceptions); err != nil {
+		// An unknown LicenseRef- may be defined elsewhere: it is only a warning unless --strict.
+		strict, _ := cmd.Flags().GetBool("strict")
+		var b strings.Builder
+		b.WriteString("invalid expression:")
+		failed := false
+		for _, e := range unwrapJoined(err) {
+			var u *expression.UnknownIDError
+			if !errors.As(e, &u) {
+				continue
+			}
+			msg := u.Error()
+			if u.Ref {
+				if s := spdx.Suggest(u.ID, refCandidates(licenses), maxSuggestions); len(s) > 0 {
+					msg += " (did you mean " + strings.Join(s, ", ") + "?)"
+				}
+				if !strict {
+					fmt.Fprintf(os.Stderr, "warning: %s\n%s\n", msg, expression.Caret(input, u.Pos))
+					continue
+				}
+			}
+			failed = true
+			fmt.Fprintf(&b, "\n%s\n%s", msg, expression.Caret(input, u.Pos))
+		}
+		if failed {
+			return &unknownIDError{report: b.String()}
+		}
+	}
+	fmt.Println(expr.String())
+	return nil
+}
+
+// unknownIDError is validate's report of unknown IDs, each with its column and a caret line. It is
+// ErrNotFound, but its message is the report alone, so that the caret line stays the last line.
+type unknownIDError struct {
+	report string
+}
+
+func (e *unknownIDError) Error() string { return e.report }
+
+func (e *unknownIDError) Unwrap() error { return ErrNotFound }
+
+// refCandidates returns the LicenseRef- IDs of licenses, to suggest for an unknown one.
+func refCandidates(licenses map[string]bool) []spdx.Candidate {
+	var candidates []spdx.Candidate
+	for id := range licenses {
+		if strings.HasPrefix(id, spdx.CustomPrefix) {
+			candidates = append(candidates, spdx.Candidate{ID: id})
+		}
+	}
+	return candidates
+}
+
+// knownIDs returns the sets of license and exception IDs from src for expression validation: the SPDX
+// ones and the LicenseRef- IDs of custom and organization registry licenses.
+func knownIDs(ctx context.Context, src spdx.Source) (licenses, exceptions map[string]bool, err error) {
+	list, err := src.LicenseList(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to fetch license list: %v", ErrIOOrNetwork, err)
+	}
+	exList, err := src.ExceptionList(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to fetch exception list: %v", ErrIOOrNetwork, err)
+	}
+	licenses = make(map[string]bool, len(list.Licenses))
+	for _, l := range list.Licenses {
+		licenses[l.LicenseID] = true
+	}
+	exceptions = make(map[string]bool, len(exList.Exceptions))
+	for _, e := range exList.Exceptions {
+		exceptions[e.LicenseExceptionID] = true
+	}
+	return licenses, exceptions, nil
+}
+
+// unwrapJoined returns the errors joined by errors.Join, or err itself.
+func unwrapJoined(err error) []error {
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().Bool("strict", false, "fail on LicenseRef- IDs that are not custom or organization licenses instead of warning")
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/spdx"
+)
+
+func TestValidateRunE_Valid(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "Classpath-exception-2.0"))
+
+	r, w, _ := os.Pipe()
+	old := os.Stdout
+	os.Stdout = w
+	err := validateCmd.RunE(validateCmd, []string{"MIT or (Apache-2.0 and GPL-2.0-only with Classpath-exception-2.0)"})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	if string(out) != "MIT OR Apache-2.0 AND GPL-2.0-only WITH Classpath-exception-2.0\n" {
+		t.Errorf("stdout = %q", out)
+	}
+}
+
+func TestValidateRunE_CaseInsensitive(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "Classpath-exception-2.0"))
+
+	out, err := runCapture(t, validateCmd, "mit OR apache-2.0 WITH classpath-exception-2.0")
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if out != "MIT OR Apache-2.0 WITH Classpath-exception-2.0\n" {
+		t.Errorf("stdout = %q, want the listed spelling", out)
+	}
+}
+
+func TestValidateRunE_UnknownLicenseRef(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "Classpath-exception-2.0"))
+	_ = os.MkdirAll(filepath.Join(dir, "licenses"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "licenses", "LicenseRef-Acme-EULA.txt"), []byte("EULA"), 0644)
+
+	if out, err := runCapture(t, validateCmd, "MIT OR LicenseRef-Acme-EULA"); err != nil || out != "MIT OR LicenseRef-Acme-EULA\n" {
+		t.Errorf("known LicenseRef- = %q, %v", out, err)
+	}
+	var out string
+	var err error
+	stderr := captureStderr(t, func() { out, err = runCapture(t, validateCmd, "MIT OR LicenseRef-Acme-EUAL") })
+	if err != nil || out != "MIT OR LicenseRef-Acme-EUAL\n" {
+		t.Errorf("unknown LicenseRef- = %q, %v; want it accepted", out, err)
+	}
+	if !strings.Contains(stderr, `warning: column 8: unknown license reference ID "LicenseRef-Acme-EUAL" (did you mean LicenseRef-Acme-EULA?)`) {
+		t.Errorf("stderr = %q, want a warning with the suggestion", stderr)
+	}
+
+	setFlag(t, validateCmd, "strict", "true")
+	if _, err := runCapture(t, validateCmd, "MIT OR LicenseRef-Acme-EUAL"); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "did you mean LicenseRef-Acme-EULA?") {
+		t.Errorf("--strict: expected ErrNotFound with a suggestion, got %v", err)
+	}
+}
+
+func TestValidateRunE_UnknownIDIsNotFound(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "Classpath-exception-2.0"))
+
+	err := validateCmd.RunE(validateCmd, []string{"MIT OR Apache-2.O"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("RunE: expected ErrNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "column 8: unknown license ID \"Apache-2.O\"\nMIT OR Apache-2.O\n       ^") {
+		t.Errorf("error lacks position/caret: %q", err)
+	}
+}
+
+func TestValidate_UnknownIDReport(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "Classpath-exception-2.0"))
+	rootCmd.SetArgs([]string{"validate", "MIT OR Apache-2.O"})
+	defer rootCmd.SetArgs(nil)
+
+	var code int
+	stderr := captureStderr(t, func() { code = Execute() })
+	if code != 2 {
+		t.Errorf("Execute() = %d, want 2", code)
+	}
+	want := "invalid expression:\ncolumn 8: unknown license ID \"Apache-2.O\"\nMIT OR Apache-2.O\n       ^\n"
+	if stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+}
+
+func TestValidateRunE_SyntaxErrorIsUsage(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	err := validateCmd.RunE(validateCmd, []string{"MIT OR"})
+	if err == nil {
+		t.Fatal("RunE: expected error")
+	}
+	if exitCodeFrom(err) != 1 {
+		t.Errorf("exit code = %d, want 1 (%v)", exitCodeFrom(err), err)
+	}
+}
+
+func TestValidateRunE_FetchErrorMapsToIOOrNetwork(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &spdx.MemorySource{Err: errors.New("connection refused")})
+
+	err := validateCmd.RunE(validateCmd, []string{"MIT"})
+	if !errors.Is(err, ErrIOOrNetwork) {
+		t.Errorf("RunE: expected ErrIOOrNetwork, got %v", err)
+	}
+}
+
+// captureStderr runs f and returns what it printed on stderr.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, _ := os.Pipe()
+	old := os.Stderr
+	os.Stderr = w
+	f()
+	w.Close()
+	os.Stderr = old
+	b, _ := io.ReadAll(r)
+	return string(b)
+}
@@ -7,22 +7,38 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/tom/ligma/internal/atomicfile"
 	"github.com/tom/ligma/internal/config"
+	"github.com/tom/ligma/internal/diff"
 	"github.com/tom/ligma/internal/spdx"
+	"github.com/tom/ligma/internal/spdx/expression"
 )
 
-// writeFetchDetails is the details fetcher; swappable for tests.
-var writeFetchDetails = spdx.FetchLicenseDetails
-
 // writeCmd represents the write command
 var writeCmd = &cobra.Command{
 	Use:   "write",
-	Short: "Write license text to a file by SPDX ID",
-	Long:  `Fetch the license for the given SPDX ID and write it to LICENSE (one arg) or to the given path (two args). With no arguments, writes the configured favorite to LICENSE in the current directory. Overwrites if the file exists.`,
+	Short: "Write license text to a file by SPDX ID or expression",
+	Long: `Write the license for the given SPDX ID to LICENSE in the current directory, or to the given path. With
+no arguments, write the configured favorite. An SPDX license exception writes the exception text; informal
+names and LicenseRef- IDs are looked up as by get.
+
+An expression such as "MIT OR Apache-2.0" writes one file per license, named by --pattern (default
+LICENSE-{short}: LICENSE-MIT and LICENSE-APACHE) in the current directory or the given one, or all of them
+into one file with --combined. The text of an exception (WITH) is appended to the license it modifies.
+
+Copyright placeholders are filled from --holder, --year, --project and --email, or their config defaults.
+A deprecated ID prints a warning naming its replacement; --strict makes it an error.
+
+An existing file with different contents is only replaced with --force, or with --backup, which keeps it
+as <path>.bak; otherwise write exits with code 4. --dry-run prints a unified diff instead of writing.
+Files are replaced atomically.`,
 	Args:  cobra.RangeArgs(0, 2),
 	SilenceUsage: true,
 	SilenceErrors: true,
@@ -34,49 +50,236 @@ func runWrite(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	template := spdx.DefaultDetailsURLTemplate
-	if cfg.SPDXGetURLTemplate != "" {
-		template = cfg.SPDXGetURLTemplate
+	src, err := cachedSource(cfg)
+	if err != nil {
+		return err
 	}
 
+	// path is the output file, or with an expression of several licenses the directory of the files.
 	var id, path string
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
 	if len(args) == 0 {
 		if cfg.Favorite == nil || *cfg.Favorite == "" {
 			return fmt.Errorf("favorite license is not set; run with <id> or set favorite in ~/.ligma/config.json")
 		}
 		id = cfg.Resolve(*cfg.Favorite)
-		cwd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("get working directory: %w", err)
-		}
-		path = filepath.Join(cwd, "LICENSE")
 	} else {
 		id = cfg.Resolve(args[0])
-		if len(args) == 2 {
-			path = args[1]
-		} else {
-			cwd, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("get working directory: %w", err)
-			}
+	}
+	if len(args) == 2 {
+		path = args[1]
+	}
+
+	id, e := resolveInput(commandContext(cmd), src, cfg.Aliases, id)
+	files := []licenseFile{{id: id}}
+	if e != nil {
+		files = licenseFiles(expression.Terms(e))
+	}
+	values := fillValues(cmd, cfg)
+	texts := make([]string, len(files))
+	for i := range files {
+		if texts[i], err = files[i].text(cmd, src, cfg.Aliases, values); err != nil {
+			return err
+		}
+	}
+
+	if combined, _ := cmd.Flags().GetBool("combined"); combined || len(files) == 1 {
+		if path == "" {
 			path = filepath.Join(cwd, "LICENSE")
 		}
+		return writeLicense(cmd, path, strings.Join(texts, licenseSeparator))
 	}
 
-	text, err := writeFetchDetails(cmd.Context(), template, id)
+	pattern, _ := cmd.Flags().GetString("pattern")
+	if pattern == "" {
+		pattern = cfg.LicenseFilePattern
+	}
+	if pattern == "" {
+		pattern = defaultLicenseFilePattern
+	}
+	if path == "" {
+		path = cwd
+	}
+	paths := make([]string, len(files))
+	byPath := make(map[string]string, len(files))
+	for i, f := range files {
+		paths[i] = filepath.Join(path, licenseFileName(pattern, f.id))
+		if other, dup := byPath[paths[i]]; dup {
+			return fmt.Errorf("pattern %q gives %s and %s the same file %s; use {id} in it", pattern, other, f.id, paths[i])
+		}
+		byPath[paths[i]] = f.id
+	}
+	// Refuse before writing anything, so that one conflicting file does not leave the others half done.
+	for i := range files {
+		if err := refuseClobber(cmd, paths[i], texts[i]); err != nil {
+			return err
+		}
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	for i := range files {
+		if !dryRun {
+			// Patterns such as LICENSES/{id}.txt name a subdirectory.
+			if err := os.MkdirAll(filepath.Dir(paths[i]), 0755); err != nil {
+				return fmt.Errorf("mkdir %s: %v: %w", filepath.Dir(paths[i]), err, ErrIOOrNetwork)
+			}
+		}
+		if err := writeLicense(cmd, paths[i], texts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultLicenseFilePattern names the file of each license of an expression the way Rust projects
+// licensed MIT OR Apache-2.0 do: LICENSE-MIT and LICENSE-APACHE.
+const defaultLicenseFilePattern = "LICENSE-{short}"
+
+// licenseSeparator goes between the licenses of a --combined file.
+var licenseSeparator = "\n" + strings.Repeat("-", 72) + "\n\n"
+
+// licenseFile is one license of an expression with the exceptions that modify it.
+type licenseFile struct {
+	id         string
+	exceptions []string
+}
+
+// licenseFiles groups terms by license, in order of first appearance: "GPL-2.0-only WITH
+// Classpath-exception-2.0 OR GPL-2.0-only" is one license with one exception.
+func licenseFiles(terms []expression.Term) []licenseFile {
+	var files []licenseFile
+	index := make(map[string]int)
+	for _, t := range terms {
+		id := t.License.String()
+		i, seen := index[strings.ToLower(id)] // SPDX IDs match case-insensitively
+		if !seen {
+			i = len(files)
+			index[strings.ToLower(id)] = i
+			files = append(files, licenseFile{id: id})
+		}
+		if t.Exception != "" && !slices.ContainsFunc(files[i].exceptions, func(ex string) bool { return strings.EqualFold(ex, t.Exception) }) {
+			files[i].exceptions = append(files[i].exceptions, t.Exception)
+		}
+	}
+	return files
+}
+
+// text returns the filled license text of f, followed by the text of each of its exceptions, and sets
+// the IDs of f to their listed spelling. Deprecated IDs are reported by checkDeprecated.
+func (f *licenseFile) text(cmd *cobra.Command, src spdx.Source, aliases map[string]string, v spdx.Values) (string, error) {
+	ctx := commandContext(cmd)
+	details, _, err := lookupText(ctx, src, aliases, f.id)
 	if err != nil {
-		if errors.Is(err, spdx.ErrNotFound) {
-			return fmt.Errorf("license not found: %s: %w", id, ErrNotFound)
+		return "", err
+	}
+	if details.LicenseID != "" {
+		f.id = details.LicenseID
+	}
+	if err := checkDeprecated(cmd, f.id, details.IsDeprecatedLicenseID); err != nil {
+		return "", err
+	}
+	text := spdx.Fill(details, v)
+	for i, ex := range f.exceptions {
+		exDetails, err := lookupException(ctx, src, aliases, ex)
+		if err != nil {
+			return "", err
+		}
+		if exDetails.LicenseID != "" {
+			f.exceptions[i] = exDetails.LicenseID
+		}
+		if err := checkDeprecated(cmd, f.exceptions[i], exDetails.IsDeprecatedLicenseID); err != nil {
+			return "", err
+		}
+		text = strings.TrimRight(text, "\n") + "\n\n" + spdx.Fill(exDetails, v)
+	}
+	return text, nil
+}
+
+// licenseFileName expands pattern for the license id: {id} is the SPDX ID and {short} its upper-case
+// family name without version or variant (Apache-2.0 → APACHE, GPL-3.0-or-later → GPL,
+// LicenseRef-Acme → ACME).
+func licenseFileName(pattern, id string) string {
+	return strings.NewReplacer("{id}", id, "{short}", shortName(id)).Replace(pattern)
+}
+
+// shortName returns id without a LicenseRef- prefix, up to the first "-" followed by a digit, in upper case.
+func shortName(id string) string {
+	id = strings.TrimPrefix(id, "LicenseRef-")
+	for i := 1; i < len(id); i++ {
+		if id[i-1] == '-' && id[i] >= '0' && id[i] <= '9' {
+			return strings.ToUpper(id[:i-1])
 		}
-		return fmt.Errorf("fetch license %s: %v: %w", id, err, ErrIOOrNetwork)
 	}
+	return strings.ToUpper(id)
+}
 
-	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+// writeLicense writes content to path unless that would clobber different contents without --force or
+// --backup (ErrExists). With --backup the replaced file is kept as path.bak; with --dry-run only the
+// diff is printed.
+func writeLicense(cmd *cobra.Command, path, content string) error {
+	old, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read %s: %v: %w", path, err, ErrIOOrNetwork)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		from := path
+		if !exists {
+			from = "/dev/null"
+		}
+		fmt.Print(diff.Unified(from, path, string(old), content, 3))
+		return nil
+	}
+	if exists && string(old) == content {
+		return nil
+	}
+	if err := refuseClobber(cmd, path, content); err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if exists {
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+		if backup, _ := cmd.Flags().GetBool("backup"); backup {
+			if err := atomicfile.WriteFile(path+".bak", old, mode); err != nil {
+				return fmt.Errorf("back up %s: %v: %w", path, err, ErrIOOrNetwork)
+			}
+		}
+	}
+	if err := atomicfile.WriteFile(path, []byte(content), mode); err != nil {
 		return fmt.Errorf("write %s: %v: %w", path, err, ErrIOOrNetwork)
 	}
 	return nil
 }
 
+// refuseClobber returns the error (ErrExists) for writing content over a different existing file at path
+// without --force or --backup; nil when the write may go ahead (or is only a --dry-run).
+func refuseClobber(cmd *cobra.Command, path, content string) error {
+	force, _ := cmd.Flags().GetBool("force")
+	backup, _ := cmd.Flags().GetBool("backup")
+	if force || backup {
+		return nil
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+	old, err := os.ReadFile(path)
+	if err != nil || string(old) == content {
+		return nil // a read error other than not-exist is reported by the write
+	}
+	return fmt.Errorf("refusing to overwrite %s: %w with different contents; use --force to replace it, --backup to replace it and keep a copy, or --dry-run to see the diff", path, ErrExists)
+}
+
 func init() {
 	rootCmd.AddCommand(writeCmd)
+	addFillFlags(writeCmd)
+	addStrictFlag(writeCmd)
+	writeCmd.Flags().BoolP("force", "f", false, "replace an existing file with different contents")
+	writeCmd.Flags().Bool("backup", false, "replace an existing file with different contents, keeping it as <path>.bak")
+	writeCmd.Flags().Bool("dry-run", false, "print a unified diff against the existing file instead of writing")
+	writeCmd.Flags().String("pattern", "", "file name per license of an expression, {id} or {short} for the license (default: license_file_pattern in config, else LICENSE-{short})")
+	writeCmd.Flags().Bool("combined", false, "write all licenses of an expression into one file")
 }
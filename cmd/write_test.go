@@ -1,10 +1,12 @@
 package cmd
 
 import (
-	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/tom/ligma/internal/config"
@@ -15,11 +17,9 @@ func TestWriteRunE_NotFound(t *testing.T) {
 	config.SetConfigDirOverride(t.TempDir())
 	defer config.SetConfigDirOverride("")
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
-		return "", spdx.ErrNotFound
-	}
-	defer func() { writeFetchDetails = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return nil, spdx.ErrNotFound
+	}})
 
 	err := writeCmd.RunE(writeCmd, []string{"x"})
 	if err == nil {
@@ -35,11 +35,9 @@ func TestWriteRunE_OneArg(t *testing.T) {
 	config.SetConfigDirOverride(dir)
 	defer config.SetConfigDirOverride("")
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
-		return "license text", nil
-	}
-	defer func() { writeFetchDetails = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "license text"}, nil
+	}})
 	orig, _ := os.Getwd()
 	_ = os.Chdir(dir)
 	defer func() { _ = os.Chdir(orig) }()
@@ -66,14 +64,12 @@ func TestWriteRunE_AliasResolved(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
 		if id != "MIT" {
-			return "", spdx.ErrNotFound
+			return nil, spdx.ErrNotFound
 		}
-		return "license text", nil
-	}
-	defer func() { writeFetchDetails = save }()
+		return &spdx.LicenseDetails{LicenseText: "license text"}, nil
+	}})
 	orig, _ := os.Getwd()
 	_ = os.Chdir(dir)
 	defer func() { _ = os.Chdir(orig) }()
@@ -96,11 +92,9 @@ func TestWriteRunE_TwoArgs(t *testing.T) {
 	config.SetConfigDirOverride(dir)
 	defer config.SetConfigDirOverride("")
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
-		return "custom text", nil
-	}
-	defer func() { writeFetchDetails = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "custom text"}, nil
+	}})
 
 	orig, _ := os.Getwd()
 	_ = os.Chdir(dir)
@@ -123,11 +117,9 @@ func TestWriteRunE_WriteFails(t *testing.T) {
 	config.SetConfigDirOverride(t.TempDir())
 	defer config.SetConfigDirOverride("")
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
-		return "x", nil
-	}
-	defer func() { writeFetchDetails = save }()
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "x"}, nil
+	}})
 
 	dir := t.TempDir() // pass dir as path: WriteFile to a directory fails
 	err := writeCmd.RunE(writeCmd, []string{"id", dir})
@@ -148,14 +140,12 @@ func TestWriteRunE_ZeroArgsFavorite(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
 		if id != "MIT" {
-			return "", spdx.ErrNotFound
+			return nil, spdx.ErrNotFound
 		}
-		return "favorite text", nil
-	}
-	defer func() { writeFetchDetails = save }()
+		return &spdx.LicenseDetails{LicenseText: "favorite text"}, nil
+	}})
 	orig, _ := os.Getwd()
 	_ = os.Chdir(dir)
 	defer func() { _ = os.Chdir(orig) }()
@@ -195,14 +185,12 @@ func TestWriteRunE_ZeroArgsFavoriteResolvedViaAlias(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
 		if id != "MIT" {
-			return "", spdx.ErrNotFound
+			return nil, spdx.ErrNotFound
 		}
-		return "from alias", nil
-	}
-	defer func() { writeFetchDetails = save }()
+		return &spdx.LicenseDetails{LicenseText: "from alias"}, nil
+	}})
 	orig, _ := os.Getwd()
 	_ = os.Chdir(dir)
 	defer func() { _ = os.Chdir(orig) }()
@@ -222,18 +210,17 @@ func TestWriteRunE_UsesConfigGetURLTemplate(t *testing.T) {
 	config.SetConfigDirOverride(dir)
 	defer config.SetConfigDirOverride("")
 
-	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spdx_get_url_template":"https://example.com/{id}.json"}`), 0644); err != nil {
-		t.Fatal(err)
-	}
-
-	save := writeFetchDetails
-	writeFetchDetails = func(ctx context.Context, template, id string) (string, error) {
-		if template != "https://example.com/{id}.json" {
-			return "", spdx.ErrNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/MIT.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
 		}
-		return "ok", nil
+		_, _ = w.Write([]byte(`{"licenseId":"MIT","licenseText":"ok"}`))
+	}))
+	defer srv.Close()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spdx_get_url_template":"`+srv.URL+`/{id}.json"}`), 0644); err != nil {
+		t.Fatal(err)
 	}
-	defer func() { writeFetchDetails = save }()
 	orig, _ := os.Getwd()
 	_ = os.Chdir(dir)
 	defer func() { _ = os.Chdir(orig) }()
@@ -247,3 +234,306 @@ func TestWriteRunE_UsesConfigGetURLTemplate(t *testing.T) {
 		t.Errorf("LICENSE = %q, want ok", got)
 	}
 }
+
+func TestWriteRunE_ExceptionFallback(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+
+	stubSource(t, &funcSource{exception: func(id string) (*spdx.ExceptionDetails, error) {
+		return &spdx.ExceptionDetails{LicenseExceptionID: id, LicenseExceptionText: "exception text"}, nil
+	}})
+
+	path := filepath.Join(dir, "EXCEPTION")
+	if err := writeCmd.RunE(writeCmd, []string{"LLVM-exception", path}); err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "exception text" {
+		t.Errorf("EXCEPTION = %q, want exception text", got)
+	}
+}
+
+func TestWriteRunE_FillsPlaceholders(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"holder":"Config Holder","year":"2020"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseText: "Copyright (c) <year> <copyright holders>"}, nil
+	}})
+
+	// --holder overrides the config default; year comes from config
+	_ = writeCmd.Flags().Set("holder", "Flag Holder")
+	defer func() { _ = writeCmd.Flags().Set("holder", "") }()
+
+	path := filepath.Join(dir, "LICENSE")
+	if err := writeCmd.RunE(writeCmd, []string{"MIT", path}); err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "Copyright (c) 2020 Flag Holder" {
+		t.Errorf("LICENSE = %q", got)
+	}
+}
+
+func TestWriteRunE_UsesCacheLikeGet(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	getSimulateIO = false
+
+	calls := 0
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		calls++
+		return &spdx.LicenseDetails{LicenseID: id, LicenseText: "license text"}, nil
+	}})
+
+	// get fills the cache; write within cache_ttl reuses it, also offline.
+	if _, err := runCapture(t, getCmd, "MIT"); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if err := writeCmd.RunE(writeCmd, []string{"MIT", filepath.Join(dir, "LICENSE")}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	offlineFlag = true
+	defer func() { offlineFlag = false }()
+	if err := writeCmd.RunE(writeCmd, []string{"MIT", filepath.Join(dir, "LICENSE2")}); err != nil {
+		t.Fatalf("write --offline: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("upstream details calls = %d, want 1", calls)
+	}
+}
+
+func TestWriteRunE_ExpressionOneFilePerLicense(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "GPL-3.0-only", "Classpath-exception-2.0"))
+	dir := t.TempDir()
+
+	if err := writeCmd.RunE(writeCmd, []string{"MIT OR Apache-2.0", dir}); err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	for name, want := range map[string]string{"LICENSE-MIT": "MIT text", "LICENSE-APACHE": "Apache-2.0 text"} {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	setFlag(t, writeCmd, "pattern", "LICENSES/{id}.txt")
+	if err := writeCmd.RunE(writeCmd, []string{"(MIT OR GPL-2.0-only WITH Classpath-exception-2.0) AND GPL-2.0-only", dir}); err != nil {
+		t.Fatalf("RunE --pattern: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "LICENSES", "GPL-2.0-only.txt")); string(got) != "GPL-2.0-only text\n\nClasspath-exception-2.0 text" {
+		t.Errorf("GPL-2.0-only.txt = %q, want the exception appended", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "LICENSES", "MIT.txt")); err != nil {
+		t.Errorf("MIT.txt: %v", err)
+	}
+}
+
+func TestWriteRunE_ExpressionPatternFromConfig(t *testing.T) {
+	cfgDir := t.TempDir()
+	config.SetConfigDirOverride(cfgDir)
+	defer config.SetConfigDirOverride("")
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.json"), []byte(`{"license_file_pattern":"LICENSE-{id}"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "GPL-3.0-only", "Classpath-exception-2.0"))
+	dir := t.TempDir()
+
+	if err := writeCmd.RunE(writeCmd, []string{"MIT OR Apache-2.0", dir}); err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	for _, name := range []string{"LICENSE-MIT", "LICENSE-Apache-2.0"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestWriteRunE_ExpressionCombined(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "GPL-3.0-only", "Classpath-exception-2.0"))
+	path := filepath.Join(t.TempDir(), "LICENSE")
+	setFlag(t, writeCmd, "combined", "true")
+
+	if err := writeCmd.RunE(writeCmd, []string{"MIT OR Apache-2.0", path}); err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "MIT text"+licenseSeparator+"Apache-2.0 text" {
+		t.Errorf("LICENSE = %q", got)
+	}
+}
+
+func TestWriteRunE_ExpressionErrors(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0", "GPL-2.0-only", "GPL-3.0-only", "Classpath-exception-2.0"))
+	dir := t.TempDir()
+
+	// An unknown ID anywhere in the expression fails before any file is written.
+	err := writeCmd.RunE(writeCmd, []string{"MIT OR Nope-1.0", dir})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown license: expected ErrNotFound, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("files written despite the error: %v", entries)
+	}
+	if err := writeCmd.RunE(writeCmd, []string{"MIT WITH Nope-exception", dir}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown exception: expected ErrNotFound, got %v", err)
+	}
+
+	// A conflicting file stops the whole write.
+	if err := os.WriteFile(filepath.Join(dir, "LICENSE-APACHE"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCmd.RunE(writeCmd, []string{"MIT OR Apache-2.0", dir}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("conflict: expected a refusal, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "LICENSE-MIT")); !os.IsNotExist(err) {
+		t.Errorf("LICENSE-MIT written despite the conflict: %v", err)
+	}
+
+	// The default pattern gives both GPLs LICENSE-GPL.
+	if err := writeCmd.RunE(writeCmd, []string{"GPL-2.0-only OR GPL-3.0-only", dir}); err == nil || !strings.Contains(err.Error(), "same file") {
+		t.Errorf("colliding {short}: expected an error, got %v", err)
+	}
+	setFlag(t, writeCmd, "pattern", "LICENSE")
+	if err := writeCmd.RunE(writeCmd, []string{"MIT OR Apache-2.0", dir}); err == nil || !strings.Contains(err.Error(), "same file") {
+		t.Errorf("pattern without {id}: expected an error, got %v", err)
+	}
+}
+
+func TestShortName(t *testing.T) {
+	for id, want := range map[string]string{"MIT": "MIT", "Apache-2.0": "APACHE", "GPL-3.0-or-later": "GPL", "BSD-3-Clause": "BSD", "0BSD": "0BSD", "CC0-1.0": "CC0", "LicenseRef-Acme-EULA-2": "ACME-EULA"} {
+		if got := shortName(id); got != want {
+			t.Errorf("shortName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestWriteRunE_RefusesToClobber(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseID: id, LicenseText: "MIT License\nCopyright (c) 2026 New\n"}, nil
+	}})
+	path := filepath.Join(dir, "LICENSE")
+	edited := "MIT License\nCopyright (c) 2019 Real Holder\n"
+	if err := os.WriteFile(path, []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeCmd.RunE(writeCmd, []string{"MIT", path})
+	if !errors.Is(err, ErrExists) || exitCodeFrom(err) != 4 || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("RunE: expected an ErrExists refusal pointing at --force, got %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != edited {
+		t.Errorf("LICENSE changed without --force: %q", got)
+	}
+
+	setFlag(t, writeCmd, "backup", "true") // implies --force
+	if err := writeCmd.RunE(writeCmd, []string{"MIT", path}); err != nil {
+		t.Fatalf("RunE --backup: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "MIT License\nCopyright (c) 2026 New\n" {
+		t.Errorf("LICENSE = %q", got)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want the replaced file's 0600", fi.Mode().Perm())
+	}
+	if got, _ := os.ReadFile(path + ".bak"); string(got) != edited {
+		t.Errorf("LICENSE.bak = %q", got)
+	}
+}
+
+func TestWriteRunE_SameContentsIsNoOp(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseID: id, LicenseText: "license text"}, nil
+	}})
+	path := filepath.Join(dir, "LICENSE")
+	if err := os.WriteFile(path, []byte("license text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCmd.RunE(writeCmd, []string{"MIT", path}); err != nil {
+		t.Errorf("RunE over identical file: %v", err)
+	}
+}
+
+func TestWriteRunE_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	config.SetConfigDirOverride(dir)
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseID: id, LicenseText: "MIT License\nnew line\n"}, nil
+	}})
+	path := filepath.Join(dir, "LICENSE")
+	if err := os.WriteFile(path, []byte("MIT License\nold line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, writeCmd, "dry-run", "true")
+
+	out, err := runCapture(t, writeCmd, "MIT", path)
+	if err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	want := "--- " + path + "\n+++ " + path + "\n@@ -1,2 +1,2 @@\n MIT License\n-old line\n+new line\n"
+	if out != want {
+		t.Errorf("diff =\n%s\nwant\n%s", out, want)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "MIT License\nold line\n" {
+		t.Errorf("--dry-run wrote the file: %q", got)
+	}
+
+	out, err = runCapture(t, writeCmd, "MIT", filepath.Join(dir, "NEW"))
+	if err != nil || !strings.HasPrefix(out, "--- /dev/null\n") {
+		t.Errorf("diff for a new file = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "NEW")); !os.IsNotExist(err) {
+		t.Errorf("--dry-run created the file: %v", err)
+	}
+}
+
+func TestWriteRunE_StrictRejectsDeprecated(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, &funcSource{details: func(id string) (*spdx.LicenseDetails, error) {
+		return &spdx.LicenseDetails{LicenseID: id, LicenseText: id + " text"}, nil
+	}})
+	dir := t.TempDir()
+	setFlag(t, writeCmd, "strict", "true")
+
+	err := writeCmd.RunE(writeCmd, []string{"MIT OR LGPL-2.1", dir})
+	if err == nil || exitCodeFrom(err) != 1 || !strings.Contains(err.Error(), "LGPL-2.1-only") {
+		t.Errorf("RunE: expected a usage error suggesting LGPL-2.1-only, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("files written despite --strict: %v", entries)
+	}
+}
+
+func TestWriteRunE_ExpressionCaseInsensitive(t *testing.T) {
+	config.SetConfigDirOverride(t.TempDir())
+	defer config.SetConfigDirOverride("")
+	stubSource(t, newFixture("MIT", "Apache-2.0"))
+	dir := t.TempDir()
+
+	if err := writeCmd.RunE(writeCmd, []string{"mit OR apache-2.0 OR MIT", dir}); err != nil {
+		t.Fatalf("RunE: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 || entries[0].Name() != "LICENSE-APACHE" || entries[1].Name() != "LICENSE-MIT" {
+		t.Errorf("files = %v, want LICENSE-APACHE and LICENSE-MIT", entries)
+	}
+}
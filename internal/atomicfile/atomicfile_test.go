@@ -0,0 +1,48 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.json")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "new" {
+		t.Errorf("content = %q, %v", b, err)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", fi.Mode().Perm())
+	}
+	if rest, _ := os.ReadDir(dir); len(rest) != 1 {
+		t.Errorf("temporary files left: %v", rest)
+	}
+}
+
+func TestWriteFile_FailureKeepsOld(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.json")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Renaming a file over a non-empty directory fails.
+	target := filepath.Join(dir, "sub")
+	_ = os.MkdirAll(filepath.Join(target, "x"), 0755)
+	if err := WriteFile(target, []byte("new"), 0644); err == nil {
+		t.Fatal("expected error writing over a directory")
+	}
+	if rest, _ := os.ReadDir(dir); len(rest) != 2 {
+		t.Errorf("temporary file left after failure: %v", rest)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "old" {
+		t.Errorf("content = %q", b)
+	}
+}
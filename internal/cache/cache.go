@@ -2,23 +2,25 @@ package cache
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/tom/ligma/internal/atomicfile"
 	"github.com/tom/ligma/internal/spdx"
 )
 
-// FetchListFn is the list fetcher; defaults to spdx.FetchLicenseList. Override in tests.
-var FetchListFn = spdx.FetchLicenseList
-
-// FetchDetailsFn is the details fetcher; defaults to spdx.FetchLicenseDetails. Override in tests.
-var FetchDetailsFn = spdx.FetchLicenseDetails
-
 const defaultTTL = 86400 // 24h in seconds
 
+// ErrNotCached is returned in offline mode when an entry is not in the cache. Its message says how to
+// fill the cache, as the user cannot do without it.
+var ErrNotCached = errors.New("not in the cache and --offline keeps ligma off the network; run `ligma sync` while online first")
+
 // TTL returns the effective cache TTL in seconds. If cfg is nil, use default. If *cfg is 0, always fetch.
 func TTL(cfg *int) int {
 	if cfg == nil {
@@ -27,100 +29,257 @@ func TTL(cfg *int) int {
 	return *cfg
 }
 
-// FetchList returns the SPDX license list, from cache if valid (mtime within ttl) or via spdx.FetchLicenseList.
-// cacheDir is ~/.ligma/_cache. ttl 0: always fetch. On cache write failure, still returns fetched data.
-func FetchList(ctx context.Context, cacheDir string, ttl int, listURL string) ([]spdx.License, error) {
-	listPath := filepath.Join(cacheDir, "list.json")
+// Source is a spdx.Source that serves from the file cache under Dir (~/.ligma/_cache) when an entry is
+// valid (mtime within TTL seconds) and otherwise fetches from Upstream and writes the result back.
+// TTL 0: always ask Upstream. On cache write failure, still returns fetched data. A corrupt entry is a
+// miss. Entries are written atomically (temp file and rename) under an advisory lock on <Dir>.lock, so
+// concurrent ligma runs never see or leave a partial file. When Upstream is an
+// spdx.ConditionalSource, each entry's ETag/Last-Modified are kept in a <entry>.meta sidecar and an
+// expired entry is revalidated instead of downloaded again.
+//
+// With OnStale set, an expired entry is served when Upstream fails (stale-if-error). With Offline set,
+// Upstream is never called: any cached entry is served whatever its age, and a missing one is ErrNotCached.
+//
+// Layout: list.json, details/<id>.json, exceptions.json, exceptions/<id>.json. IDs are used as-is for
+// the path; an ID containing ".." or path separators skips the cache. Each entry has a <entry>.meta
+// sidecar (see Meta). Callers keep data of different sources apart by giving each its own Dir (see
+// Namespace).
+type Source struct {
+	Dir      string
+	TTL      int
+	Upstream spdx.Source
+	// SourceID and ListVersion describe Upstream in each entry's Meta.
+	SourceID    string
+	ListVersion string
+	// Offline serves only what is cached.
+	Offline bool
+	// OnStale, if set, enables stale-if-error and is called with the fetch error each time an expired
+	// entry is served in place of fresh data. spdx.ErrNotFound is never answered with a stale entry.
+	OnStale func(err error)
+	// Logf, if set, reports cache write failures and corrupt entries (which are otherwise only misses).
+	Logf func(format string, args ...any)
+}
 
-	if ttl == 0 {
-		list, err := FetchListFn(ctx, listURL)
-		tryWriteList(cacheDir, listPath, list)
-		return list, err
-	}
+// Meta is the metadata kept in the <entry>.meta sidecar of a cache entry: where and when it was
+// fetched, the SHA-256 of the entry file, and the HTTP validators used to revalidate it.
+type Meta struct {
+	Source      string    `json:"source,omitempty"`
+	ListVersion string    `json:"listVersion,omitempty"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+	SHA256      string    `json:"sha256"`
+	spdx.Validators
+}
 
-	fi, err := os.Stat(listPath)
-	if err == nil && time.Since(fi.ModTime()) < time.Duration(ttl)*time.Second {
-		return readListFile(listPath)
+// Namespace returns the cache subdirectory name for data from source (e.g. its URLs) at listVersion:
+// a short hash, so that pointing the config at another source or version never serves old entries.
+func Namespace(source, listVersion string) string {
+	h := sha256.Sum256([]byte(source + "\x00" + listVersion))
+	return hex.EncodeToString(h[:8])
+}
+
+// New returns a cache-backed Source over upstream.
+func New(dir string, ttl int, upstream spdx.Source) *Source {
+	return &Source{Dir: dir, TTL: ttl, Upstream: upstream}
+}
+
+// LicenseList implements spdx.Source. The cached list.json keeps the full licenses.json document.
+func (s *Source) LicenseList(ctx context.Context) (*spdx.LicenseList, error) {
+	return load(s, filepath.Join(s.Dir, "list.json"), func(prev spdx.Validators) (*spdx.LicenseList, spdx.Validators, error) {
+		if c, ok := s.Upstream.(spdx.ConditionalSource); ok {
+			return c.LicenseListIf(ctx, prev)
+		}
+		list, err := s.Upstream.LicenseList(ctx)
+		return list, spdx.Validators{}, err
+	})
+}
+
+// LicenseDetails implements spdx.Source with entries under details/.
+func (s *Source) LicenseDetails(ctx context.Context, id string) (*spdx.LicenseDetails, error) {
+	if !cacheableID(id) {
+		if s.Offline {
+			return nil, ErrNotCached
+		}
+		return s.Upstream.LicenseDetails(ctx, id)
 	}
+	return load(s, filepath.Join(s.Dir, "details", id+".json"), func(prev spdx.Validators) (*spdx.LicenseDetails, spdx.Validators, error) {
+		if c, ok := s.Upstream.(spdx.ConditionalSource); ok {
+			return c.LicenseDetailsIf(ctx, id, prev)
+		}
+		d, err := s.Upstream.LicenseDetails(ctx, id)
+		return d, spdx.Validators{}, err
+	})
+}
 
-	list, err := FetchListFn(ctx, listURL)
-	tryWriteList(cacheDir, listPath, list)
-	return list, err
+// ExceptionList implements spdx.Source with exceptions.json.
+func (s *Source) ExceptionList(ctx context.Context) (*spdx.ExceptionList, error) {
+	return load(s, filepath.Join(s.Dir, "exceptions.json"), func(prev spdx.Validators) (*spdx.ExceptionList, spdx.Validators, error) {
+		if c, ok := s.Upstream.(spdx.ConditionalSource); ok {
+			return c.ExceptionListIf(ctx, prev)
+		}
+		list, err := s.Upstream.ExceptionList(ctx)
+		return list, spdx.Validators{}, err
+	})
 }
 
-type listFile struct {
-	Licenses []spdx.License `json:"licenses"`
+// ExceptionDetails implements spdx.Source with entries under exceptions/.
+func (s *Source) ExceptionDetails(ctx context.Context, id string) (*spdx.ExceptionDetails, error) {
+	if !cacheableID(id) {
+		if s.Offline {
+			return nil, ErrNotCached
+		}
+		return s.Upstream.ExceptionDetails(ctx, id)
+	}
+	return load(s, filepath.Join(s.Dir, "exceptions", id+".json"), func(prev spdx.Validators) (*spdx.ExceptionDetails, spdx.Validators, error) {
+		if c, ok := s.Upstream.(spdx.ConditionalSource); ok {
+			return c.ExceptionDetailsIf(ctx, id, prev)
+		}
+		d, err := s.Upstream.ExceptionDetails(ctx, id)
+		return d, spdx.Validators{}, err
+	})
 }
 
-func readListFile(path string) ([]spdx.License, error) {
-	b, err := os.ReadFile(path)
+// load returns the cache entry at path when it is valid. Otherwise it calls fetch with the validators
+// stored for an expired entry (zero when there are none): on spdx.ErrNotModified the expired entry is
+// served and its mtime refreshed, without a download; new data is written back with its validators.
+// A failed fetch falls back to the expired entry when OnStale is set; offline, fetch is never called.
+func load[T any](s *Source, path string, fetch func(prev spdx.Validators) (*T, spdx.Validators, error)) (*T, error) {
+	var cached T
+	if s.read(path, &cached) {
+		return &cached, nil
+	}
+	if s.Offline {
+		var stale T
+		if !readStale(path, &stale) {
+			return nil, ErrNotCached
+		}
+		return &stale, nil
+	}
+	prev := readMeta(path).Validators
+	fetched, next, err := fetch(prev)
+	if errors.Is(err, spdx.ErrNotModified) {
+		var stale T
+		if readStale(path, &stale) {
+			now := time.Now()
+			_ = os.Chtimes(path, now, now)
+			return &stale, nil
+		}
+		// The entry vanished or broke since the validators were stored: fetch it in full.
+		fetched, next, err = fetch(spdx.Validators{})
+	}
 	if err != nil {
+		var stale T
+		if s.OnStale != nil && !errors.Is(err, spdx.ErrNotFound) && readStale(path, &stale) {
+			s.OnStale(err)
+			return &stale, nil
+		}
 		return nil, err
 	}
-	var f listFile
-	if err := json.Unmarshal(b, &f); err != nil {
-		return nil, err
+	if err := s.write(path, fetched, next); err != nil {
+		s.logf("cache: write %s: %v", path, err)
 	}
-	return f.Licenses, nil
+	return fetched, nil
 }
 
-func tryWriteList(cacheDir, listPath string, list []spdx.License) {
-	_ = os.MkdirAll(cacheDir, 0755)
-	b, err := json.Marshal(listFile{Licenses: list})
-	if err != nil {
-		return
-	}
-	_ = os.WriteFile(listPath, b, 0644)
+// LicenseDetailsFresh reports whether the cached details of id are still valid, i.e. LicenseDetails
+// would serve them without asking Upstream.
+func (s *Source) LicenseDetailsFresh(id string) bool {
+	return cacheableID(id) && s.fresh(filepath.Join(s.Dir, "details", id+".json"))
 }
 
-// FetchDetails returns the license text for id, from cache if valid or via spdx.FetchLicenseDetails.
-// cacheDir is ~/.ligma/_cache. ttlSec 0: always fetch. On cache write failure, still returns fetched data.
-// ID is used as-is for the path (e.g. details/MIT.json); if id contains ".." or path separators, cache is skipped.
-func FetchDetails(ctx context.Context, cacheDir string, ttl int, template, id string) (string, error) {
-	if strings.Contains(id, "..") || strings.ContainsAny(id, `/\`) {
-		return FetchDetailsFn(ctx, template, id)
-	}
+// ExceptionDetailsFresh is LicenseDetailsFresh for exceptions/.
+func (s *Source) ExceptionDetailsFresh(id string) bool {
+	return cacheableID(id) && s.fresh(filepath.Join(s.Dir, "exceptions", id+".json"))
+}
 
-	detailsPath := filepath.Join(cacheDir, "details", id+".json")
+func cacheableID(id string) bool {
+	return !strings.Contains(id, "..") && !strings.ContainsAny(id, `/\`)
+}
 
-	if ttl == 0 {
-		text, err := FetchDetailsFn(ctx, template, id)
-		tryWriteDetails(cacheDir, detailsPath, text)
-		return text, err
+// read decodes the cache entry at path into v when it is valid. ok is false on a miss: TTL 0, no
+// file, an expired file, or one that cannot be read or decoded.
+func (s *Source) read(path string, v any) (ok bool) {
+	if !s.fresh(path) {
+		return false
 	}
+	b, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	if err != nil {
+		s.logf("cache: ignoring unreadable entry %s: %v", path, err)
+		return false
+	}
+	return true
+}
+
+// readStale decodes the entry at path into v whatever its age; false if it is missing or unreadable.
+func readStale(path string, v any) bool {
+	b, err := os.ReadFile(path)
+	return err == nil && json.Unmarshal(b, v) == nil
+}
 
-	fi, err := os.Stat(detailsPath)
-	if err == nil && time.Since(fi.ModTime()) < time.Duration(ttl)*time.Second {
-		return readDetailsFile(detailsPath)
+// fresh reports whether the entry at path exists and is younger than TTL (never with TTL 0).
+func (s *Source) fresh(path string) bool {
+	if s.TTL == 0 {
+		return false
 	}
+	fi, err := os.Stat(path)
+	return err == nil && time.Since(fi.ModTime()) < time.Duration(s.TTL)*time.Second
+}
 
-	text, err := FetchDetailsFn(ctx, template, id)
-	tryWriteDetails(cacheDir, detailsPath, text)
-	return text, err
+// metaPath is the sidecar holding the HTTP validators of the cache entry at path.
+func metaPath(path string) string {
+	return path + ".meta"
 }
 
-type detailsFile struct {
-	LicenseText string `json:"licenseText"`
+// readMeta returns the metadata stored for the entry at path; zero if the entry or its sidecar is
+// missing or unreadable.
+func readMeta(path string) Meta {
+	var m Meta
+	if _, err := os.Stat(path); err != nil {
+		return m
+	}
+	b, err := os.ReadFile(metaPath(path))
+	if err != nil || json.Unmarshal(b, &m) != nil {
+		return Meta{}
+	}
+	return m
 }
 
-func readDetailsFile(path string) (string, error) {
-	b, err := os.ReadFile(path)
+// write stores v as JSON at path with its Meta sidecar, creating parent directories. Both files are
+// replaced atomically while holding the lock on the cache directory.
+func (s *Source) write(path string, v any, validators spdx.Validators) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockDir(s.Dir)
 	if err != nil {
-		return "", err
+		return err
 	}
-	var f detailsFile
-	if err := json.Unmarshal(b, &f); err != nil {
-		return "", err
+	defer unlock()
+	if err := atomicfile.WriteFile(path, b, 0644); err != nil {
+		return err
 	}
-	return f.LicenseText, nil
+	sum := sha256.Sum256(b)
+	m, err := json.Marshal(Meta{
+		Source:      s.SourceID,
+		ListVersion: s.ListVersion,
+		FetchedAt:   time.Now().UTC().Truncate(time.Second),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Validators:  validators,
+	})
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(metaPath(path), m, 0644)
 }
 
-func tryWriteDetails(cacheDir, detailsPath, text string) {
-	dir := filepath.Dir(detailsPath)
-	_ = os.MkdirAll(dir, 0755)
-	b, err := json.Marshal(detailsFile{LicenseText: text})
-	if err != nil {
-		return
+func (s *Source) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
 	}
-	_ = os.WriteFile(detailsPath, b, 0644)
 }
@@ -2,23 +2,59 @@ package cache
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/tom/ligma/internal/config"
 	"github.com/tom/ligma/internal/spdx"
 )
 
-func TestFetchList_Miss(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
+// failSource fails the test if the cache consults upstream.
+type failSource struct{ t *testing.T }
+
+func (f failSource) LicenseList(context.Context) (*spdx.LicenseList, error) {
+	f.t.Fatal("upstream should not be called on cache hit")
+	return nil, nil
+}
+
+func (f failSource) LicenseDetails(context.Context, string) (*spdx.LicenseDetails, error) {
+	f.t.Fatal("upstream should not be called on cache hit")
+	return nil, nil
+}
+
+func (f failSource) ExceptionList(context.Context) (*spdx.ExceptionList, error) {
+	f.t.Fatal("upstream should not be called on cache hit")
+	return nil, nil
+}
+
+func (f failSource) ExceptionDetails(context.Context, string) (*spdx.ExceptionDetails, error) {
+	f.t.Fatal("upstream should not be called on cache hit")
+	return nil, nil
+}
+
+func memList(ids ...string) *spdx.MemorySource {
+	list := &spdx.LicenseList{}
+	for _, id := range ids {
+		list.Licenses = append(list.Licenses, spdx.License{LicenseID: id, Name: id})
+	}
+	return &spdx.MemorySource{Licenses: list}
+}
+
+func memDetails(id, text string) *spdx.MemorySource {
+	return &spdx.MemorySource{LicenseDetailsByID: map[string]*spdx.LicenseDetails{id: {LicenseID: id, LicenseText: text}}}
+}
 
+func TestLicenseList_Miss(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -26,12 +62,12 @@ func TestFetchList_Miss(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	cacheDir := filepath.Join(dir, "_cache")
-	list, err := FetchList(context.Background(), cacheDir, 3600, srv.URL)
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	list, err := New(cacheDir, 3600, &spdx.HTTPSource{ListURL: srv.URL}).LicenseList(context.Background())
 	if err != nil {
-		t.Fatalf("FetchList: %v", err)
+		t.Fatalf("LicenseList: %v", err)
 	}
-	if len(list) != 1 || list[0].LicenseID != "MIT" {
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "MIT" {
 		t.Errorf("list = %+v", list)
 	}
 	// cache file should exist
@@ -40,12 +76,31 @@ func TestFetchList_Miss(t *testing.T) {
 	}
 }
 
-func TestFetchList_Hit(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
+func TestLicenseList_KeepsMetadata(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	upstream := &spdx.MemorySource{Licenses: &spdx.LicenseList{
+		LicenseListVersion: "3.24",
+		ReleaseDate:        "2024-05-22",
+		Licenses:           []spdx.License{{LicenseID: "MIT", IsOsiApproved: true, IsFsfLibre: true, SeeAlso: []string{"https://opensource.org/license/mit/"}}},
+	}}
+	if _, err := New(cacheDir, 3600, upstream).LicenseList(context.Background()); err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	// second call is served from list.json
+	list, err := New(cacheDir, 3600, failSource{t}).LicenseList(context.Background())
+	if err != nil {
+		t.Fatalf("LicenseList (cached): %v", err)
+	}
+	if list.LicenseListVersion != "3.24" || list.ReleaseDate != "2024-05-22" {
+		t.Errorf("cached version/releaseDate = %q/%q", list.LicenseListVersion, list.ReleaseDate)
+	}
+	if l := list.Licenses[0]; !l.IsOsiApproved || !l.IsFsfLibre || len(l.SeeAlso) != 1 {
+		t.Errorf("cached license = %+v", l)
+	}
+}
 
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseList_Hit(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(cacheDir, 0755)
 	listPath := filepath.Join(cacheDir, "list.json")
 	_ = os.WriteFile(listPath, []byte(`{"licenses":[{"licenseId":"X","name":"X"}]}`), 0644)
@@ -53,28 +108,17 @@ func TestFetchList_Hit(t *testing.T) {
 	// ensure mtime is recent
 	_ = os.Chtimes(listPath, time.Now(), time.Now())
 
-	// use a fetcher that would fail if called (no server)
-	FetchListFn = func(ctx context.Context, url string) ([]spdx.License, error) {
-		t.Fatal("fetcher should not be called on cache hit")
-		return nil, nil
-	}
-	defer func() { FetchListFn = spdx.FetchLicenseList }()
-
-	list, err := FetchList(context.Background(), cacheDir, 3600, "http://unused")
+	list, err := New(cacheDir, 3600, failSource{t}).LicenseList(context.Background())
 	if err != nil {
-		t.Fatalf("FetchList: %v", err)
+		t.Fatalf("LicenseList: %v", err)
 	}
-	if len(list) != 1 || list[0].LicenseID != "X" {
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "X" {
 		t.Errorf("list = %+v", list)
 	}
 }
 
-func TestFetchList_Stale(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseList_Stale(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(cacheDir, 0755)
 	listPath := filepath.Join(cacheDir, "list.json")
 	_ = os.WriteFile(listPath, []byte(`{"licenses":[{"licenseId":"old","name":"Old"}]}`), 0644)
@@ -82,91 +126,59 @@ func TestFetchList_Stale(t *testing.T) {
 	old := time.Now().Add(-2 * time.Hour)
 	_ = os.Chtimes(listPath, old, old)
 
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"licenses":[{"licenseId":"NEW","name":"New"}]}`))
-	}))
-	defer srv.Close()
-
-	list, err := FetchList(context.Background(), cacheDir, 3600, srv.URL)
+	list, err := New(cacheDir, 3600, memList("NEW")).LicenseList(context.Background())
 	if err != nil {
-		t.Fatalf("FetchList: %v", err)
+		t.Fatalf("LicenseList: %v", err)
 	}
-	if len(list) != 1 || list[0].LicenseID != "NEW" {
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "NEW" {
 		t.Errorf("list = %+v, want NEW", list)
 	}
 }
 
-func TestFetchList_TTLZero(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseList_TTLZero(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(cacheDir, 0755)
 	_ = os.WriteFile(filepath.Join(cacheDir, "list.json"), []byte(`{"licenses":[{"licenseId":"cached","name":"Cached"}]}`), 0644)
 	_ = os.Chtimes(filepath.Join(cacheDir, "list.json"), time.Now(), time.Now())
 
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"licenses":[{"licenseId":"fresh","name":"Fresh"}]}`))
-	}))
-	defer srv.Close()
-
-	list, err := FetchList(context.Background(), cacheDir, 0, srv.URL)
+	list, err := New(cacheDir, 0, memList("fresh")).LicenseList(context.Background())
 	if err != nil {
-		t.Fatalf("FetchList: %v", err)
+		t.Fatalf("LicenseList: %v", err)
 	}
-	if len(list) != 1 || list[0].LicenseID != "fresh" {
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "fresh" {
 		t.Errorf("list = %+v, want fresh (ttl 0 must bypass cache)", list)
 	}
 }
 
-func TestFetchList_WriteFailureStillReturns(t *testing.T) {
+func TestLicenseList_WriteFailureStillReturns(t *testing.T) {
 	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"licenses":[{"licenseId":"OK","name":"OK"}]}`))
-	}))
-	defer srv.Close()
-
 	// cacheDir is a file, not a dir, so MkdirAll/WriteFile will fail
 	cacheDir := filepath.Join(dir, "obstacle")
 	_ = os.WriteFile(cacheDir, []byte("x"), 0644)
 
-	list, err := FetchList(context.Background(), cacheDir, 3600, srv.URL)
+	var logged []string
+	s := New(cacheDir, 3600, memList("OK"))
+	s.Logf = func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+	list, err := s.LicenseList(context.Background())
 	if err != nil {
-		t.Fatalf("FetchList: %v (should return data despite write failure)", err)
+		t.Fatalf("LicenseList: %v (should return data despite write failure)", err)
 	}
-	if len(list) != 1 || list[0].LicenseID != "OK" {
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "OK" {
 		t.Errorf("list = %+v", list)
 	}
-}
-
-func TestFetchDetails_Miss(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	save := FetchDetailsFn
-	FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "license text", nil
+	if len(logged) != 1 {
+		t.Errorf("write failure logged as %q, want one message", logged)
 	}
-	defer func() { FetchDetailsFn = save }()
+}
 
-	cacheDir := filepath.Join(dir, "_cache")
-	text, err := FetchDetails(context.Background(), cacheDir, 3600, "http://x/{id}.json", "MIT")
+func TestLicenseDetails_Miss(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	d, err := New(cacheDir, 3600, memDetails("MIT", "license text")).LicenseDetails(context.Background(), "MIT")
 	if err != nil {
-		t.Fatalf("FetchDetails: %v", err)
+		t.Fatalf("LicenseDetails: %v", err)
 	}
-	if text != "license text" {
-		t.Errorf("text = %q", text)
+	if d.LicenseText != "license text" {
+		t.Errorf("text = %q", d.LicenseText)
 	}
 	p := filepath.Join(cacheDir, "details", "MIT.json")
 	if _, err := os.Stat(p); os.IsNotExist(err) {
@@ -174,12 +186,8 @@ func TestFetchDetails_Miss(t *testing.T) {
 	}
 }
 
-func TestFetchDetails_Hit(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseDetails_Hit(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	detailsDir := filepath.Join(cacheDir, "details")
 	_ = os.MkdirAll(detailsDir, 0755)
 	path := filepath.Join(detailsDir, "MIT.json")
@@ -187,28 +195,17 @@ func TestFetchDetails_Hit(t *testing.T) {
 	_ = os.WriteFile(path, b, 0644)
 	_ = os.Chtimes(path, time.Now(), time.Now())
 
-	save := FetchDetailsFn
-	FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		t.Fatal("fetcher should not be called on cache hit")
-		return "", nil
-	}
-	defer func() { FetchDetailsFn = save }()
-
-	text, err := FetchDetails(context.Background(), cacheDir, 3600, "http://unused/{id}.json", "MIT")
+	d, err := New(cacheDir, 3600, failSource{t}).LicenseDetails(context.Background(), "MIT")
 	if err != nil {
-		t.Fatalf("FetchDetails: %v", err)
+		t.Fatalf("LicenseDetails: %v", err)
 	}
-	if text != "cached text" {
-		t.Errorf("text = %q, want cached text", text)
+	if d.LicenseText != "cached text" {
+		t.Errorf("text = %q, want cached text", d.LicenseText)
 	}
 }
 
-func TestFetchDetails_Stale(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseDetails_Stale(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(filepath.Join(cacheDir, "details"), 0755)
 	path := filepath.Join(cacheDir, "details", "MIT.json")
 	b, _ := json.Marshal(struct{ LicenseText string }{"old"})
@@ -216,125 +213,224 @@ func TestFetchDetails_Stale(t *testing.T) {
 	old := time.Now().Add(-2 * time.Hour)
 	_ = os.Chtimes(path, old, old)
 
-	save := FetchDetailsFn
-	FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "fresh", nil
-	}
-	defer func() { FetchDetailsFn = save }()
-
-	text, err := FetchDetails(context.Background(), cacheDir, 3600, "http://unused/{id}.json", "MIT")
+	d, err := New(cacheDir, 3600, memDetails("MIT", "fresh")).LicenseDetails(context.Background(), "MIT")
 	if err != nil {
-		t.Fatalf("FetchDetails: %v", err)
+		t.Fatalf("LicenseDetails: %v", err)
 	}
-	if text != "fresh" {
-		t.Errorf("text = %q, want fresh (stale should refetch)", text)
+	if d.LicenseText != "fresh" {
+		t.Errorf("text = %q, want fresh (stale should refetch)", d.LicenseText)
 	}
 }
 
-func TestFetchDetails_TTLZero(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseDetails_TTLZero(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(filepath.Join(cacheDir, "details"), 0755)
 	path := filepath.Join(cacheDir, "details", "MIT.json")
 	b, _ := json.Marshal(struct{ LicenseText string }{"cached"})
 	_ = os.WriteFile(path, b, 0644)
 	_ = os.Chtimes(path, time.Now(), time.Now())
 
-	save := FetchDetailsFn
-	FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "fresh", nil
-	}
-	defer func() { FetchDetailsFn = save }()
-
-	text, err := FetchDetails(context.Background(), cacheDir, 0, "http://unused/{id}.json", "MIT")
+	d, err := New(cacheDir, 0, memDetails("MIT", "fresh")).LicenseDetails(context.Background(), "MIT")
 	if err != nil {
-		t.Fatalf("FetchDetails: %v", err)
+		t.Fatalf("LicenseDetails: %v", err)
 	}
-	if text != "fresh" {
-		t.Errorf("text = %q, want fresh (ttl 0 must bypass cache)", text)
+	if d.LicenseText != "fresh" {
+		t.Errorf("text = %q, want fresh (ttl 0 must bypass cache)", d.LicenseText)
 	}
 }
 
-func TestFetchDetails_pathTraversalSkipsCache(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseDetails_pathTraversalSkipsCache(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(filepath.Join(cacheDir, "details"), 0755)
 
-	save := FetchDetailsFn
-	FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "from-fetcher", nil
-	}
-	defer func() { FetchDetailsFn = save }()
-
-	text, err := FetchDetails(context.Background(), cacheDir, 3600, "http://x/{id}.json", "a/b")
+	d, err := New(cacheDir, 3600, memDetails("a/b", "from-upstream")).LicenseDetails(context.Background(), "a/b")
 	if err != nil {
-		t.Fatalf("FetchDetails: %v", err)
+		t.Fatalf("LicenseDetails: %v", err)
 	}
-	if text != "from-fetcher" {
-		t.Errorf("text = %q, want from-fetcher (id with / skips cache read, calls fetcher)", text)
+	if d.LicenseText != "from-upstream" {
+		t.Errorf("text = %q, want from-upstream (id with / skips cache read, calls upstream)", d.LicenseText)
 	}
 }
 
-func TestFetchList_CorruptJSONReturnsError(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
+func TestLicenseDetails_NotFoundNotCached(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	_, err := New(cacheDir, 3600, &spdx.MemorySource{}).LicenseDetails(context.Background(), "Nope")
+	if !errors.Is(err, spdx.ErrNotFound) {
+		t.Fatalf("err = %v, want spdx.ErrNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "details", "Nope.json")); !os.IsNotExist(err) {
+		t.Error("a 404 must not leave a cache entry")
+	}
+}
 
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseList_CorruptJSONIsMiss(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	path := filepath.Join(cacheDir, "list.json")
 	_ = os.MkdirAll(cacheDir, 0755)
-	_ = os.WriteFile(filepath.Join(cacheDir, "list.json"), []byte(`{invalid`), 0644)
-	_ = os.Chtimes(filepath.Join(cacheDir, "list.json"), time.Now(), time.Now())
+	_ = os.WriteFile(path, []byte(`{"licenses":[{"licen`), 0644) // truncated by an interrupted write
+	_ = os.Chtimes(path, time.Now(), time.Now())
 
-	_, err := FetchList(context.Background(), cacheDir, 3600, "http://unused")
-	if err == nil {
-		t.Fatal("FetchList: expected error for corrupt cache JSON")
+	var logged int
+	s := New(cacheDir, 3600, memList("MIT"))
+	s.Logf = func(string, ...any) { logged++ }
+	list, err := s.LicenseList(context.Background())
+	if err != nil || len(list.Licenses) != 1 {
+		t.Fatalf("LicenseList = %+v, %v; want a refetch", list, err)
+	}
+	if logged != 1 {
+		t.Errorf("corrupt entry logged %d times, want 1", logged)
+	}
+	// The refetch repaired the entry.
+	if _, err := New(cacheDir, 3600, failSource{t}).LicenseList(context.Background()); err != nil {
+		t.Errorf("LicenseList after repair: %v", err)
 	}
 }
 
-func TestFetchDetails_CorruptJSONReturnsError(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	cacheDir := filepath.Join(dir, "_cache")
+func TestLicenseDetails_CorruptJSONIsMiss(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(filepath.Join(cacheDir, "details"), 0755)
 	_ = os.WriteFile(filepath.Join(cacheDir, "details", "MIT.json"), []byte(`{invalid`), 0644)
 	_ = os.Chtimes(filepath.Join(cacheDir, "details", "MIT.json"), time.Now(), time.Now())
 
-	_, err := FetchDetails(context.Background(), cacheDir, 3600, "http://unused/{id}.json", "MIT")
-	if err == nil {
-		t.Fatal("FetchDetails: expected error for corrupt cache JSON")
+	d, err := New(cacheDir, 3600, memDetails("MIT", "fresh")).LicenseDetails(context.Background(), "MIT")
+	if err != nil || d.LicenseText != "fresh" {
+		t.Fatalf("LicenseDetails = %+v, %v; want a refetch", d, err)
 	}
 }
 
-func TestFetchDetails_WriteFailureStillReturns(t *testing.T) {
-	dir := t.TempDir()
-	config.SetConfigDirOverride(dir)
-	defer config.SetConfigDirOverride("")
-
-	save := FetchDetailsFn
-	FetchDetailsFn = func(ctx context.Context, template, id string) (string, error) {
-		return "fetched", nil
-	}
-	defer func() { FetchDetailsFn = save }()
-
+func TestLicenseDetails_WriteFailureStillReturns(t *testing.T) {
 	// _cache/details as a file so writing MIT.json under it fails
-	cacheDir := filepath.Join(dir, "_cache")
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
 	_ = os.MkdirAll(cacheDir, 0755)
 	_ = os.WriteFile(filepath.Join(cacheDir, "details"), []byte("x"), 0644)
 
-	text, err := FetchDetails(context.Background(), cacheDir, 3600, "http://x/{id}.json", "MIT")
+	d, err := New(cacheDir, 3600, memDetails("MIT", "fetched")).LicenseDetails(context.Background(), "MIT")
+	if err != nil {
+		t.Fatalf("LicenseDetails: %v (should return data despite write failure)", err)
+	}
+	if d.LicenseText != "fetched" {
+		t.Errorf("text = %q", d.LicenseText)
+	}
+}
+
+func TestExceptionList_MissThenHit(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	upstream := &spdx.MemorySource{Exceptions: &spdx.ExceptionList{LicenseListVersion: "3.24", Exceptions: []spdx.Exception{{LicenseExceptionID: "LLVM-exception", Name: "LLVM Exception"}}}}
+	if _, err := New(cacheDir, 3600, upstream).ExceptionList(context.Background()); err != nil {
+		t.Fatalf("ExceptionList: %v", err)
+	}
+	list, err := New(cacheDir, 3600, failSource{t}).ExceptionList(context.Background())
+	if err != nil {
+		t.Fatalf("ExceptionList (cached): %v", err)
+	}
+	if list.LicenseListVersion != "3.24" || len(list.Exceptions) != 1 || list.Exceptions[0].LicenseExceptionID != "LLVM-exception" {
+		t.Errorf("list = %+v", list)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "exceptions.json")); err != nil {
+		t.Errorf("exceptions.json should have been written: %v", err)
+	}
+}
+
+func TestExceptionDetails_MissThenHit(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	upstream := &spdx.MemorySource{ExceptionDetailsByID: map[string]*spdx.ExceptionDetails{
+		"LLVM-exception": {LicenseExceptionID: "LLVM-exception", LicenseExceptionText: "exception text"},
+	}}
+	if _, err := New(cacheDir, 3600, upstream).ExceptionDetails(context.Background(), "LLVM-exception"); err != nil {
+		t.Fatalf("ExceptionDetails: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "exceptions", "LLVM-exception.json")); err != nil {
+		t.Errorf("exceptions/LLVM-exception.json should have been written: %v", err)
+	}
+	d, err := New(cacheDir, 3600, failSource{t}).ExceptionDetails(context.Background(), "LLVM-exception")
 	if err != nil {
-		t.Fatalf("FetchDetails: %v (should return data despite write failure)", err)
+		t.Fatalf("ExceptionDetails (cached): %v", err)
 	}
-	if text != "fetched" {
-		t.Errorf("text = %q", text)
+	if d.LicenseExceptionText != "exception text" {
+		t.Errorf("text = %q", d.LicenseExceptionText)
+	}
+}
+
+func TestLicenseList_RevalidatesWithValidators(t *testing.T) {
+	var full, notModified int
+	etag := `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Wed, 01 Jan 2025 00:00:00 GMT")
+		_, _ = w.Write([]byte(`{"licenseListVersion":` + strconv.Quote(etag) + `,"licenses":[{"licenseId":"MIT"}]}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	src := New(dir, 60, &spdx.HTTPSource{ListURL: srv.URL})
+	path := filepath.Join(dir, "list.json")
+	if _, err := src.LicenseList(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path + ".meta")
+	if err != nil {
+		t.Fatalf("validators not stored: %v", err)
+	}
+	var v spdx.Validators
+	if err := json.Unmarshal(b, &v); err != nil || v.ETag != etag || v.LastModified == "" {
+		t.Errorf("meta = %s, %v", b, err)
+	}
+
+	// Expired entry, unchanged upstream: 304, entry served and its mtime refreshed.
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(path, old, old)
+	list, err := src.LicenseList(context.Background())
+	if err != nil || len(list.Licenses) != 1 {
+		t.Fatalf("LicenseList after 304 = %+v, %v", list, err)
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("requests: %d full, %d not modified; want 1, 1", full, notModified)
+	}
+	if fi, _ := os.Stat(path); time.Since(fi.ModTime()) > time.Minute {
+		t.Errorf("mtime not refreshed after 304: %v", fi.ModTime())
+	}
+
+	// Expired entry, changed upstream: downloaded and stored with the new validators.
+	_ = os.Chtimes(path, old, old)
+	etag = `"v2"`
+	list, err = src.LicenseList(context.Background())
+	if err != nil || list.LicenseListVersion != `"v2"` || full != 2 {
+		t.Fatalf("LicenseList after change = %+v, %v (full=%d)", list, err, full)
+	}
+	b, _ = os.ReadFile(path + ".meta")
+	if err := json.Unmarshal(b, &v); err != nil || v.ETag != `"v2"` {
+		t.Errorf("meta after change = %s", b)
+	}
+}
+
+func TestLicenseDetails_TTLZeroRevalidates(t *testing.T) {
+	var full int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("Last-Modified", "Wed, 01 Jan 2025 00:00:00 GMT")
+		_, _ = w.Write([]byte(`{"licenseId":"MIT","licenseText":"text"}`))
+	}))
+	defer srv.Close()
+
+	src := New(t.TempDir(), 0, &spdx.HTTPSource{DetailsURLTemplate: srv.URL + "/{id}.json"})
+	for i := 0; i < 2; i++ {
+		d, err := src.LicenseDetails(context.Background(), "MIT")
+		if err != nil || d.LicenseText != "text" {
+			t.Fatalf("LicenseDetails #%d = %+v, %v", i, d, err)
+		}
+	}
+	if full != 1 {
+		t.Errorf("full downloads = %d, want 1 (second call revalidated)", full)
 	}
 }
 
@@ -351,3 +447,152 @@ func TestTTL(t *testing.T) {
 		t.Errorf("TTL(100) = %d, want 100", TTL(&n))
 	}
 }
+
+func TestLicenseDetailsFresh(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	s := New(cacheDir, 3600, memDetails("MIT", "text"))
+	if s.LicenseDetailsFresh("MIT") {
+		t.Error("fresh before any fetch")
+	}
+	if _, err := s.LicenseDetails(context.Background(), "MIT"); err != nil {
+		t.Fatalf("LicenseDetails: %v", err)
+	}
+	if !s.LicenseDetailsFresh("MIT") {
+		t.Error("not fresh right after a fetch")
+	}
+	path := filepath.Join(cacheDir, "details", "MIT.json")
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(path, old, old)
+	if s.LicenseDetailsFresh("MIT") {
+		t.Error("fresh after TTL expired")
+	}
+	if New(cacheDir, 0, nil).ExceptionDetailsFresh("MIT") || s.LicenseDetailsFresh("../MIT") {
+		t.Error("TTL 0 or an uncacheable ID reported fresh")
+	}
+}
+
+// expiredEntry writes body as the cache entry rel under a new cache dir, two hours old.
+func expiredEntry(t *testing.T, rel, body string) string {
+	t.Helper()
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	path := filepath.Join(cacheDir, rel)
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(path, old, old)
+	return cacheDir
+}
+
+func TestLicenseList_StaleIfError(t *testing.T) {
+	cacheDir := expiredEntry(t, "list.json", `{"licenses":[{"licenseId":"old"}]}`)
+	down := &spdx.MemorySource{Err: errors.New("connection refused")}
+
+	// Without OnStale the fetch error stands.
+	if _, err := New(cacheDir, 3600, down).LicenseList(context.Background()); err == nil {
+		t.Fatal("LicenseList without OnStale: expected error")
+	}
+
+	var warned error
+	s := New(cacheDir, 3600, down)
+	s.OnStale = func(err error) { warned = err }
+	list, err := s.LicenseList(context.Background())
+	if err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	if len(list.Licenses) != 1 || list.Licenses[0].LicenseID != "old" {
+		t.Errorf("list = %+v, want the expired entry", list)
+	}
+	if warned == nil {
+		t.Error("OnStale not called")
+	}
+}
+
+func TestLicenseDetails_StaleIfErrorNotForNotFound(t *testing.T) {
+	cacheDir := expiredEntry(t, "details/Gone.json", `{"licenseId":"Gone","licenseText":"old"}`)
+	s := New(cacheDir, 3600, &spdx.MemorySource{})
+	s.OnStale = func(err error) { t.Errorf("OnStale(%v) for an authoritative 404", err) }
+	if _, err := s.LicenseDetails(context.Background(), "Gone"); !errors.Is(err, spdx.ErrNotFound) {
+		t.Errorf("LicenseDetails err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	cacheDir := expiredEntry(t, "details/MIT.json", `{"licenseId":"MIT","licenseText":"cached"}`)
+	s := New(cacheDir, 3600, &spdx.MemorySource{Err: errors.New("upstream must not be called")})
+	s.Offline = true
+	ctx := context.Background()
+
+	if d, err := s.LicenseDetails(ctx, "MIT"); err != nil || d.LicenseText != "cached" {
+		t.Errorf("LicenseDetails(MIT) = %+v, %v; want the expired entry", d, err)
+	}
+	if _, err := s.LicenseDetails(ctx, "ISC"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("LicenseDetails(ISC) err = %v, want ErrNotCached", err)
+	}
+	if _, err := s.LicenseList(ctx); !errors.Is(err, ErrNotCached) {
+		t.Errorf("LicenseList err = %v, want ErrNotCached", err)
+	}
+	if _, err := s.ExceptionDetails(ctx, "../x"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("ExceptionDetails(../x) err = %v, want ErrNotCached", err)
+	}
+}
+
+func TestMeta_Written(t *testing.T) {
+	dir := t.TempDir()
+	s := New(dir, 3600, memDetails("MIT", "text"))
+	s.SourceID, s.ListVersion = "https://spdx.example/{id}.json", "v3.24.0"
+	if _, err := s.LicenseDetails(context.Background(), "MIT"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "details", "MIT.json")
+	body, _ := os.ReadFile(path)
+	m := readMeta(path)
+	sum := sha256.Sum256(body)
+	if m.Source != s.SourceID || m.ListVersion != "v3.24.0" || m.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("meta = %+v", m)
+	}
+	if time.Since(m.FetchedAt) > time.Minute {
+		t.Errorf("FetchedAt = %v", m.FetchedAt)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	a := Namespace("https://a.example/licenses.json", "")
+	if a != Namespace("https://a.example/licenses.json", "") {
+		t.Error("Namespace is not stable")
+	}
+	if a == Namespace("https://b.example/licenses.json", "") || a == Namespace("https://a.example/licenses.json", "v3.24.0") {
+		t.Error("Namespace does not depend on source and list version")
+	}
+	if len(a) != 16 {
+		t.Errorf("Namespace = %q, want 16 hex digits", a)
+	}
+}
+
+func TestConcurrentWritersLeaveAWholeEntry(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "_cache")
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, 200+i) // entries of different sizes
+			for j := range ids {
+				ids[j] = fmt.Sprintf("L-%d-%d", i, j)
+			}
+			if _, err := New(cacheDir, 0, memList(ids...)).LicenseList(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := Entries(cacheDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %v, %v", entries, err)
+	}
+	if err := Verify(entries[0]); err != nil {
+		t.Errorf("entry after concurrent writes: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry kinds, after the file that holds them.
+const (
+	KindLicenseList   = "licenses"   // list.json
+	KindLicense       = "license"    // details/<id>.json
+	KindExceptionList = "exceptions" // exceptions.json
+	KindException     = "exception"  // exceptions/<id>.json
+)
+
+// Entry is one file of a cache tree, as found by Entries.
+type Entry struct {
+	// Namespace is the Source directory relative to the cache root ("." for entries at the root,
+	// written before caches were namespaced).
+	Namespace string    `json:"namespace"`
+	Kind      string    `json:"kind"`
+	ID        string    `json:"id,omitempty"` // license or exception ID; empty for the lists
+	Path      string    `json:"path"`
+	Size      int64     `json:"bytes"`
+	ModTime   time.Time `json:"modTime"`
+	Meta      Meta      `json:"meta"` // zero when the entry has no sidecar
+}
+
+// Entries returns every cache entry under root, in all namespaces, sorted by namespace, kind and ID.
+// A missing root has no entries.
+func Entries(root string) ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		e, ok := entryAt(root, path)
+		if !ok {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		e.Size, e.ModTime, e.Meta = fi.Size(), fi.ModTime(), readMeta(path)
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+	return entries, nil
+}
+
+// entryAt classifies the file at path by the Source layout; ok is false for files that are not entries.
+func entryAt(root, path string) (Entry, bool) {
+	dir, name := filepath.Split(path)
+	dir = filepath.Clean(dir)
+	e := Entry{Path: path}
+	switch {
+	case filepath.Base(dir) == "details":
+		e.Kind, e.ID, dir = KindLicense, strings.TrimSuffix(name, ".json"), filepath.Dir(dir)
+	case filepath.Base(dir) == "exceptions":
+		e.Kind, e.ID, dir = KindException, strings.TrimSuffix(name, ".json"), filepath.Dir(dir)
+	case name == "list.json":
+		e.Kind = KindLicenseList
+	case name == "exceptions.json":
+		e.Kind = KindExceptionList
+	default:
+		return Entry{}, false
+	}
+	ns, err := filepath.Rel(root, dir)
+	if err != nil {
+		return Entry{}, false
+	}
+	e.Namespace = filepath.ToSlash(ns)
+	return e, true
+}
+
+// Verify checks that e is valid JSON and, when its sidecar has one, matches the stored SHA-256.
+func Verify(e Entry) error {
+	b, err := os.ReadFile(e.Path)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(b) {
+		return fmt.Errorf("invalid JSON")
+	}
+	if e.Meta.SHA256 != "" {
+		sum := sha256.Sum256(b)
+		if got := hex.EncodeToString(sum[:]); got != e.Meta.SHA256 {
+			return fmt.Errorf("checksum mismatch: sha256 %s, recorded %s", got, e.Meta.SHA256)
+		}
+	}
+	return nil
+}
+
+// Remove deletes e and its sidecar, holding the lock that Source.write takes on their directory.
+func Remove(e Entry) error {
+	unlock, err := lockDir(namespaceDir(e))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return remove(e)
+}
+
+// RemoveCorrupt verifies e and, when Verify fails, deletes it and its sidecar, all under the lock of
+// their directory: an entry is never judged by a sidecar a concurrent write has not replaced yet. It
+// returns Verify's error, nil for a sound entry, and err for a failed lock or removal.
+func RemoveCorrupt(e Entry) (corrupt, err error) {
+	unlock, err := lockDir(namespaceDir(e))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if corrupt = Verify(e); corrupt == nil {
+		return nil, nil
+	}
+	return corrupt, remove(e)
+}
+
+func remove(e Entry) error {
+	if err := os.Remove(e.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := os.Remove(metaPath(e.Path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cache: %w", err)
+	}
+	return nil
+}
+
+// namespaceDir returns the Source directory that holds e.
+func namespaceDir(e Entry) string {
+	dir := filepath.Dir(e.Path)
+	if e.Kind == KindLicense || e.Kind == KindException {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// lockDir takes the lock on the Source directory dir, the file dir.lock next to it.
+func lockDir(dir string) (unlock func(), err error) {
+	unlock, err = lockFile(filepath.Clean(dir) + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("cache: lock: %w", err)
+	}
+	return unlock, nil
+}
+
+// RemoveEmptyDirs deletes the directories under root (not root itself) left empty, e.g. after Remove.
+func RemoveEmptyDirs(root string) {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	// Deepest first, so that a parent emptied by its children goes too.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i]) // fails, as intended, when not empty
+	}
+}
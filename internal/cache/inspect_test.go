@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tom/ligma/internal/spdx"
+)
+
+// fillCache caches a license list, MIT's details and LLVM-exception's details under root/ns.
+func fillCache(t *testing.T, root, ns string) {
+	t.Helper()
+	up := &spdx.MemorySource{
+		Licenses:             &spdx.LicenseList{Licenses: []spdx.License{{LicenseID: "MIT"}}},
+		LicenseDetailsByID:   map[string]*spdx.LicenseDetails{"MIT": {LicenseID: "MIT", LicenseText: "text"}},
+		ExceptionDetailsByID: map[string]*spdx.ExceptionDetails{"LLVM-exception": {LicenseExceptionID: "LLVM-exception", LicenseExceptionText: "text"}},
+	}
+	s := New(filepath.Join(root, ns), 3600, up)
+	ctx := context.Background()
+	if _, err := s.LicenseList(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LicenseDetails(ctx, "MIT"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ExceptionDetails(ctx, "LLVM-exception"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "_cache")
+	if entries, err := Entries(root); err != nil || len(entries) != 0 {
+		t.Fatalf("Entries of a missing root = %v, %v", entries, err)
+	}
+	fillCache(t, root, "aaaa")
+	fillCache(t, root, ".") // pre-namespace layout
+
+	entries, err := Entries(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Namespace+" "+e.Kind+" "+e.ID)
+		if e.Size == 0 || e.Meta.SHA256 == "" {
+			t.Errorf("entry %+v: missing size or meta", e)
+		}
+	}
+	want := []string{". exception LLVM-exception", ". license MIT", ". licenses ", "aaaa exception LLVM-exception", "aaaa license MIT", "aaaa licenses "}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entries[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestVerifyAndRemove(t *testing.T) {
+	root := t.TempDir()
+	fillCache(t, root, "ns")
+	entries, _ := Entries(root)
+	for _, e := range entries {
+		if err := Verify(e); err != nil {
+			t.Errorf("Verify(%s): %v", e.Path, err)
+		}
+	}
+
+	mit := filepath.Join(root, "ns", "details", "MIT.json")
+	if err := os.WriteFile(mit, []byte(`{"licenseId":"MIT","licenseText":"tampered"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list := filepath.Join(root, "ns", "list.json")
+	if err := os.WriteFile(list, []byte(`{"licenses":[`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = Entries(root)
+	bad := 0
+	for _, e := range entries {
+		if Verify(e) != nil {
+			bad++
+			if err := Remove(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if bad != 2 {
+		t.Errorf("corrupt entries = %d, want 2 (checksum and JSON)", bad)
+	}
+	if _, err := os.Stat(mit + ".meta"); !os.IsNotExist(err) {
+		t.Errorf("sidecar left behind: %v", err)
+	}
+
+	for _, e := range entries {
+		_ = Remove(e)
+	}
+	RemoveEmptyDirs(root)
+	if rest, _ := filepath.Glob(filepath.Join(root, "*[^k]")); len(rest) != 0 { // all but ns.lock
+		t.Errorf("left after removing everything: %v", rest)
+	}
+}
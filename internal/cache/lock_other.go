@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cache
+
+// lockFile is a no-op where flock is not available; atomic renames still keep every entry whole.
+func lockFile(path string) (unlock func(), err error) {
+	return func() {}, nil
+}
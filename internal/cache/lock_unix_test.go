@@ -0,0 +1,41 @@
+//go:build unix
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRemove_WaitsForLock(t *testing.T) {
+	root := t.TempDir()
+	fillCache(t, root, "ns")
+	entries, _ := Entries(root)
+	mit := filepath.Join(root, "ns", "details", "MIT.json")
+	var e Entry
+	for _, e = range entries {
+		if e.Path == mit {
+			break
+		}
+	}
+
+	unlock, err := lockFile(filepath.Join(root, "ns.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- Remove(e) }()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := os.Stat(mit); err != nil {
+		t.Errorf("MIT removed while a write held the lock: %v", err)
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mit + ".meta"); !os.IsNotExist(err) {
+		t.Errorf("sidecar left behind: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package comment wraps text as a source-code comment in a given style (e.g. for license headers).
+package comment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// style describes how to comment out a block: an optional opening and closing line, and a per-line prefix.
+type style struct {
+	open, prefix, close string
+}
+
+var styles = map[string]style{
+	"go":        {prefix: "//"},
+	"slash":     {prefix: "//"},
+	"c":         {open: "/*", prefix: " *", close: " */"},
+	"hash":      {prefix: "#"},
+	"xml":       {open: "<!--", prefix: " ", close: "-->"},
+	"dash":      {prefix: "--"},
+	"semicolon": {prefix: ";;"},
+	"percent":   {prefix: "%"},
+	"rem":       {prefix: "REM"},
+}
+
+// Styles returns the supported style names, sorted.
+func Styles() []string {
+	names := make([]string, 0, len(styles))
+	for name := range styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Wrap returns text as a comment in the named style (see Styles). Each line gets the style's prefix
+// followed by a space; blank lines get the bare prefix so no trailing whitespace is produced.
+// Leading and trailing blank lines of text are dropped. An unknown style is an error.
+func Wrap(text, name string) (string, error) {
+	st, ok := styles[name]
+	if !ok {
+		return "", fmt.Errorf("unknown comment style %q (supported: %s)", name, strings.Join(Styles(), ", "))
+	}
+	var b strings.Builder
+	if st.open != "" {
+		b.WriteString(st.open + "\n")
+	}
+	for _, line := range strings.Split(strings.Trim(text, "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			b.WriteString(strings.TrimRight(st.prefix, " ") + "\n")
+			continue
+		}
+		b.WriteString(strings.TrimRight(st.prefix, " ") + " " + line + "\n")
+	}
+	if st.close != "" {
+		b.WriteString(st.close + "\n")
+	}
+	return b.String(), nil
+}
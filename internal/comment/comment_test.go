@@ -0,0 +1,42 @@
+package comment
+
+import "testing"
+
+func TestWrap(t *testing.T) {
+	text := "Copyright 2026 Acme\n\nLicensed under the Apache License.\n"
+	tests := []struct {
+		style, want string
+	}{
+		{"go", "// Copyright 2026 Acme\n//\n// Licensed under the Apache License.\n"},
+		{"hash", "# Copyright 2026 Acme\n#\n# Licensed under the Apache License.\n"},
+		{"c", "/*\n * Copyright 2026 Acme\n *\n * Licensed under the Apache License.\n */\n"},
+		{"xml", "<!--\n Copyright 2026 Acme\n\n Licensed under the Apache License.\n-->\n"},
+		{"dash", "-- Copyright 2026 Acme\n--\n-- Licensed under the Apache License.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			got, err := Wrap(text, tt.style)
+			if err != nil {
+				t.Fatalf("Wrap: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Wrap(%s) = %q, want %q", tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap_UnknownStyle(t *testing.T) {
+	if _, err := Wrap("x", "cobol"); err == nil {
+		t.Fatal("Wrap: expected error for unknown style")
+	}
+}
+
+func TestStyles_Sorted(t *testing.T) {
+	s := Styles()
+	for i := 1; i < len(s); i++ {
+		if s[i-1] > s[i] {
+			t.Fatalf("Styles() not sorted: %v", s)
+		}
+	}
+}
@@ -41,18 +41,83 @@ func LigmaDir() (string, error) {
 	return filepath.Join(home, ".ligma"), nil
 }
 
-// Config holds the parsed config. Only favorite, aliases, spdx_list_url, spdx_get_url_template, cache_ttl (NFR-S1: no secrets, no PII).
+// CustomLicenseDirs returns the directories of user-defined LicenseRef- licenses that exist, in order of
+// precedence: the project's .ligma/licenses (the nearest one from the working directory up, not going
+// past the home directory) and then the user's ~/.ligma/licenses.
+func CustomLicenseDirs() ([]string, error) {
+	ligmaDir, err := LigmaDir()
+	if err != nil {
+		return nil, err
+	}
+	user := filepath.Join(ligmaDir, "licenses")
+	var dirs []string
+	home, _ := os.UserHomeDir()
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("config: working directory: %w", err)
+	}
+	for dir != home {
+		project := filepath.Join(dir, ".ligma", "licenses")
+		if fi, err := os.Stat(project); err == nil && fi.IsDir() && project != user {
+			dirs = append(dirs, project)
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if fi, err := os.Stat(user); err == nil && fi.IsDir() {
+		dirs = append(dirs, user)
+	}
+	return dirs, nil
+}
+
+// Config holds the parsed ~/.ligma/config.json; it never holds secrets.
 type Config struct {
-	Favorite           *string
-	Aliases            map[string]string
-	SPDXListURL        string
-	SPDXGetURLTemplate string
-	CacheTTL           *int
+	Favorite           *string           // license written by write with no arguments
+	Aliases            map[string]string // the user's names for IDs; they win over the registry's
+	Source             string            // license data backend: "http" (default), "embedded" or "dir:<path>"
+	LicenseListVersion string            // pinned SPDX license list version, e.g. "3.24"; empty = latest (main)
+
+	SPDXListURL              string // licenses.json
+	SPDXGetURLTemplate       string // license details, with {id}
+	SPDXExceptionsURL        string // exceptions.json
+	SPDXExceptionURLTemplate string // exception details, with {id}
+	// Mirrors tried in turn after the URL above them fails; each spdx_* key also accepts a list.
+	SPDXListMirrors              []string
+	SPDXGetURLTemplateMirrors    []string
+	SPDXExceptionsMirrors        []string
+	SPDXExceptionTemplateMirrors []string
+
+	OrgRegistryURL                    string   // organization license index: http(s) URL, file: URL or path; empty = none
+	OrgRegistryMirrors                []string // mirrors of OrgRegistryURL
+	OrgRegistryDetailsURLTemplate     string   // registry license details, with {id}; empty = details/{id}.json next to the index
+	OrgRegistryDetailsTemplateMirrors []string // mirrors of OrgRegistryDetailsURLTemplate
+
+	CacheTTL         *int              // seconds a cache entry stays fresh; nil = default (24h), 0 = always revalidate
+	HTTPTimeout      *int              // seconds per request attempt; nil = default (30)
+	HTTPRetries      *int              // retries after a transient failure; nil = default (2), 0 = none
+	HTTPRetryMaxWait *int              // seconds; cap on each backoff or Retry-After wait; nil = default (30)
+	HTTPProxy        string            // proxy URL; empty = HTTPS_PROXY/HTTP_PROXY/NO_PROXY
+	HTTPCAFiles      []string          // extra root CA PEM files
+	HTTPClientCert   string            // client certificate PEM file
+	HTTPClientKey    string            // its key; empty when HTTPClientCert holds both
+	HTTPAuthTokenEnv map[string]string // host -> name of the environment variable holding its bearer token
+
+	LicenseFilePattern string // file name per license when writing an expression, e.g. "LICENSES/{id}.txt"; empty = default
+	Holder             string // default for the copyright holder placeholder
+	Year               string // default for the year placeholder; empty = the current year when other values are set
+	Project            string // default for the project name placeholder
+	Email              string // default for the email placeholder
 }
 
 const (
-	defaultListURL        = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/licenses.json"
-	defaultDetailsURLTmpl = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/details/{id}.json"
+	defaultListURL                 = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/licenses.json"
+	defaultDetailsURLTmpl          = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/details/{id}.json"
+	defaultExceptionsURL           = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/exceptions.json"
+	defaultExceptionDetailsURLTmpl = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/exceptions/{id}.json"
 )
 
 // Load creates ~/.ligma/ and ~/.ligma/config.json if absent (with {}), then reads and parses config.
@@ -84,14 +149,46 @@ func Load() (*Config, error) {
 	v.SetConfigFile(path)
 	v.SetDefault("spdx_list_url", defaultListURL)
 	v.SetDefault("spdx_get_url_template", defaultDetailsURLTmpl)
+	v.SetDefault("spdx_exceptions_url", defaultExceptionsURL)
+	v.SetDefault("spdx_exception_url_template", defaultExceptionDetailsURLTmpl)
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("config: read %s: %w", path, err)
 	}
 
 	cfg := &Config{
-		SPDXListURL:        v.GetString("spdx_list_url"),
-		SPDXGetURLTemplate: v.GetString("spdx_get_url_template"),
+		Source:             v.GetString("source"),
+		LicenseListVersion: v.GetString("license_list_version"),
 		Aliases:            v.GetStringMapString("aliases"),
+		HTTPProxy:          v.GetString("http_proxy"),
+		HTTPCAFiles:        v.GetStringSlice("http_ca_files"),
+		HTTPClientCert:     v.GetString("http_client_cert"),
+		HTTPClientKey:      v.GetString("http_client_key"),
+		HTTPAuthTokenEnv:   v.GetStringMapString("http_auth_token_env"),
+		LicenseFilePattern: v.GetString("license_file_pattern"),
+		Holder:             v.GetString("holder"),
+		Year:               v.GetString("year"),
+		Project:            v.GetString("project"),
+		Email:              v.GetString("email"),
+	}
+	for key, dst := range map[string]struct {
+		url     *string
+		mirrors *[]string
+	}{
+		"spdx_list_url":                     {&cfg.SPDXListURL, &cfg.SPDXListMirrors},
+		"spdx_get_url_template":             {&cfg.SPDXGetURLTemplate, &cfg.SPDXGetURLTemplateMirrors},
+		"spdx_exceptions_url":               {&cfg.SPDXExceptionsURL, &cfg.SPDXExceptionsMirrors},
+		"spdx_exception_url_template":       {&cfg.SPDXExceptionURLTemplate, &cfg.SPDXExceptionTemplateMirrors},
+		"org_registry_url":                  {&cfg.OrgRegistryURL, &cfg.OrgRegistryMirrors},
+		"org_registry_details_url_template": {&cfg.OrgRegistryDetailsURLTemplate, &cfg.OrgRegistryDetailsTemplateMirrors},
+	} {
+		if !v.IsSet(key) {
+			continue
+		}
+		urls, err := urlList(v.Get(key))
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", key, err)
+		}
+		*dst.url, *dst.mirrors = urls[0], urls[1:]
 	}
 	if cfg.Aliases == nil {
 		cfg.Aliases = make(map[string]string)
@@ -101,9 +198,38 @@ func Load() (*Config, error) {
 			cfg.Favorite = &s
 		}
 	}
-	if v.IsSet("cache_ttl") {
-		n := v.GetInt("cache_ttl")
-		cfg.CacheTTL = &n
+	for key, dst := range map[string]**int{
+		"cache_ttl":           &cfg.CacheTTL,
+		"http_timeout":        &cfg.HTTPTimeout,
+		"http_retries":        &cfg.HTTPRetries,
+		"http_retry_max_wait": &cfg.HTTPRetryMaxWait,
+	} {
+		if v.IsSet(key) {
+			n := v.GetInt(key)
+			*dst = &n
+		}
 	}
 	return cfg, nil
 }
+
+// urlList reads a URL key that holds either one URL or a non-empty ordered list of mirror URLs.
+func urlList(raw any) ([]string, error) {
+	switch x := raw.(type) {
+	case string:
+		return []string{x}, nil
+	case []any:
+		urls := make([]string, 0, len(x))
+		for _, e := range x {
+			s, ok := e.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("mirror list entries must be non-empty strings")
+			}
+			urls = append(urls, s)
+		}
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("mirror list is empty")
+		}
+		return urls, nil
+	}
+	return nil, fmt.Errorf("want a URL or a list of URLs, got %T", raw)
+}
@@ -54,11 +54,17 @@ func TestLoad_EmptyFile_Defaults(t *testing.T) {
 	if cfg.SPDXGetURLTemplate != defaultDetailsURLTmpl {
 		t.Errorf("SPDXGetURLTemplate = %q, want %q", cfg.SPDXGetURLTemplate, defaultDetailsURLTmpl)
 	}
+	if cfg.SPDXExceptionsURL != defaultExceptionsURL {
+		t.Errorf("SPDXExceptionsURL = %q, want %q", cfg.SPDXExceptionsURL, defaultExceptionsURL)
+	}
+	if cfg.SPDXExceptionURLTemplate != defaultExceptionDetailsURLTmpl {
+		t.Errorf("SPDXExceptionURLTemplate = %q, want %q", cfg.SPDXExceptionURLTemplate, defaultExceptionDetailsURLTmpl)
+	}
 	if cfg.Favorite != nil {
 		t.Errorf("Favorite = %v, want nil", cfg.Favorite)
 	}
-	if cfg.CacheTTL != nil {
-		t.Errorf("CacheTTL = %v, want nil", cfg.CacheTTL)
+	if cfg.CacheTTL != nil || cfg.HTTPTimeout != nil || cfg.HTTPRetries != nil {
+		t.Errorf("CacheTTL = %v, HTTPTimeout = %v, HTTPRetries = %v, want nil", cfg.CacheTTL, cfg.HTTPTimeout, cfg.HTTPRetries)
 	}
 	if cfg.Aliases == nil || len(cfg.Aliases) != 0 {
 		t.Errorf("Aliases = %v, want empty map", cfg.Aliases)
@@ -75,7 +81,7 @@ func TestLoad_WithContent(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Pre-create with content
-	body := `{"favorite":"MIT","aliases":{"apache":"Apache-2.0"},"cache_ttl":0}`
+	body := `{"favorite":"MIT","aliases":{"apache":"Apache-2.0"},"cache_ttl":0,"http_timeout":5,"http_retries":0,"http_retry_max_wait":60,"source":"dir:/opt/license-list-data/json","license_list_version":"3.24","spdx_exceptions_url":"https://mirror.example/exceptions.json","spdx_exception_url_template":"https://mirror.example/exceptions/{id}.json"}`
 	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
 		t.Fatal(err)
 	}
@@ -93,6 +99,100 @@ func TestLoad_WithContent(t *testing.T) {
 	if cfg.CacheTTL == nil || *cfg.CacheTTL != 0 {
 		t.Errorf("CacheTTL = %v, want *0", cfg.CacheTTL)
 	}
+	if cfg.HTTPTimeout == nil || *cfg.HTTPTimeout != 5 || cfg.HTTPRetries == nil || *cfg.HTTPRetries != 0 || cfg.HTTPRetryMaxWait == nil || *cfg.HTTPRetryMaxWait != 60 {
+		t.Errorf("HTTP settings = %v, %v, %v", cfg.HTTPTimeout, cfg.HTTPRetries, cfg.HTTPRetryMaxWait)
+	}
+	if cfg.Source != "dir:/opt/license-list-data/json" {
+		t.Errorf("Source = %q", cfg.Source)
+	}
+	if cfg.LicenseListVersion != "3.24" {
+		t.Errorf("LicenseListVersion = %q", cfg.LicenseListVersion)
+	}
+	if cfg.SPDXExceptionsURL != "https://mirror.example/exceptions.json" {
+		t.Errorf("SPDXExceptionsURL = %q", cfg.SPDXExceptionsURL)
+	}
+	if cfg.SPDXExceptionURLTemplate != "https://mirror.example/exceptions/{id}.json" {
+		t.Errorf("SPDXExceptionURLTemplate = %q", cfg.SPDXExceptionURLTemplate)
+	}
+}
+
+func TestLoad_PlaceholderDefaults(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDirOverride(dir)
+	defer SetConfigDirOverride("")
+
+	body := `{"holder":"Acme Corp","year":2024,"project":"widget","email":"legal@acme.example"}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Holder != "Acme Corp" || cfg.Year != "2024" || cfg.Project != "widget" || cfg.Email != "legal@acme.example" {
+		t.Errorf("placeholders = %q/%q/%q/%q", cfg.Holder, cfg.Year, cfg.Project, cfg.Email)
+	}
+}
+
+func TestLoad_URLMirrors(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDirOverride(dir)
+	defer SetConfigDirOverride("")
+
+	body := `{"spdx_list_url":["https://mirror.internal/licenses.json","https://fallback.example/licenses.json"],"spdx_get_url_template":["https://mirror.internal/{id}.json"]}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.SPDXListURL != "https://mirror.internal/licenses.json" || len(cfg.SPDXListMirrors) != 1 || cfg.SPDXListMirrors[0] != "https://fallback.example/licenses.json" {
+		t.Errorf("list URL = %q, mirrors %v", cfg.SPDXListURL, cfg.SPDXListMirrors)
+	}
+	if cfg.SPDXGetURLTemplate != "https://mirror.internal/{id}.json" || len(cfg.SPDXGetURLTemplateMirrors) != 0 {
+		t.Errorf("details template = %q, mirrors %v", cfg.SPDXGetURLTemplate, cfg.SPDXGetURLTemplateMirrors)
+	}
+	if cfg.SPDXExceptionsURL != defaultExceptionsURL || cfg.SPDXExceptionsMirrors == nil || len(cfg.SPDXExceptionsMirrors) != 0 {
+		t.Errorf("exceptions URL = %q, mirrors %v", cfg.SPDXExceptionsURL, cfg.SPDXExceptionsMirrors)
+	}
+
+	for _, bad := range []string{`{"spdx_list_url":[]}`, `{"spdx_list_url":[1]}`, `{"spdx_list_url":42}`} {
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(); err == nil {
+			t.Errorf("Load(%s): expected error", bad)
+		}
+	}
+}
+
+func TestLoad_OrgRegistry(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDirOverride(dir)
+	defer SetConfigDirOverride("")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.OrgRegistryURL != "" || cfg.OrgRegistryDetailsURLTemplate != "" {
+		t.Errorf("registry without config = %q, %q; want none", cfg.OrgRegistryURL, cfg.OrgRegistryDetailsURLTemplate)
+	}
+
+	body := `{"org_registry_url":["https://licenses.corp.example/licenses.json","https://backup.corp.example/licenses.json"],"org_registry_details_url_template":"https://licenses.corp.example/d/{id}.json"}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.OrgRegistryURL != "https://licenses.corp.example/licenses.json" || len(cfg.OrgRegistryMirrors) != 1 || cfg.OrgRegistryMirrors[0] != "https://backup.corp.example/licenses.json" {
+		t.Errorf("registry URL = %q, mirrors %v", cfg.OrgRegistryURL, cfg.OrgRegistryMirrors)
+	}
+	if cfg.OrgRegistryDetailsURLTemplate != "https://licenses.corp.example/d/{id}.json" {
+		t.Errorf("registry details template = %q", cfg.OrgRegistryDetailsURLTemplate)
+	}
 }
 
 func TestLoad_FavoriteEmptyStringYieldsNil(t *testing.T) {
@@ -151,6 +251,42 @@ func TestLigmaDir_UserHomeDir(t *testing.T) {
 	}
 }
 
+func TestCustomLicenseDirs(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDirOverride(dir)
+	defer SetConfigDirOverride("")
+	project := t.TempDir()
+	sub := filepath.Join(project, "a", "b")
+	_ = os.MkdirAll(sub, 0755)
+	orig, _ := os.Getwd()
+	_ = os.Chdir(sub)
+	defer func() { _ = os.Chdir(orig) }()
+
+	if dirs, err := CustomLicenseDirs(); err != nil || len(dirs) != 0 {
+		t.Errorf("no licenses dirs: got %v, %v", dirs, err)
+	}
+	user := filepath.Join(dir, "licenses")
+	nearest := filepath.Join(project, "a", ".ligma", "licenses")
+	_ = os.MkdirAll(user, 0755)
+	_ = os.MkdirAll(nearest, 0755)
+	_ = os.MkdirAll(filepath.Join(project, ".ligma", "licenses"), 0755)
+	dirs, err := CustomLicenseDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || !sameDir(dirs[0], nearest) || dirs[1] != user {
+		t.Errorf("CustomLicenseDirs = %v, want [%s %s]", dirs, nearest, user)
+	}
+}
+
+// sameDir reports whether a and b are the same directory, as the working directory may be reported
+// through a symlink.
+func sameDir(a, b string) bool {
+	fa, errA := os.Stat(a)
+	fb, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(fa, fb)
+}
+
 func TestLoad_InvalidJSONReturnsError(t *testing.T) {
 	dir := t.TempDir()
 	SetConfigDirOverride(dir)
@@ -217,3 +353,27 @@ func TestLoad_WithoutOverrideUsesUserHomeDir(t *testing.T) {
 		t.Error("LigmaDir empty")
 	}
 }
+
+func TestLoad_HTTPTransportKeys(t *testing.T) {
+	dir := t.TempDir()
+	SetConfigDirOverride(dir)
+	defer SetConfigDirOverride("")
+
+	body := `{"http_proxy":"http://proxy.corp:3128","http_ca_files":["/etc/corp/root.pem","/etc/corp/sub.pem"],"http_client_cert":"/etc/corp/me.pem","http_client_key":"/etc/corp/me.key","http_auth_token_env":{"mirror.corp.example":"CORP_SPDX_TOKEN"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.HTTPProxy != "http://proxy.corp:3128" || cfg.HTTPClientCert != "/etc/corp/me.pem" || cfg.HTTPClientKey != "/etc/corp/me.key" {
+		t.Errorf("proxy/cert/key = %q, %q, %q", cfg.HTTPProxy, cfg.HTTPClientCert, cfg.HTTPClientKey)
+	}
+	if len(cfg.HTTPCAFiles) != 2 || cfg.HTTPCAFiles[1] != "/etc/corp/sub.pem" {
+		t.Errorf("HTTPCAFiles = %v", cfg.HTTPCAFiles)
+	}
+	if cfg.HTTPAuthTokenEnv["mirror.corp.example"] != "CORP_SPDX_TOKEN" {
+		t.Errorf("HTTPAuthTokenEnv = %v", cfg.HTTPAuthTokenEnv)
+	}
+}
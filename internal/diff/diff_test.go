@@ -0,0 +1,58 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	a := "MIT License\n\nCopyright (c) 2020 Acme\n\nPermission is hereby granted\n"
+	b := "MIT License\n\nCopyright (c) 2026 Acme Corp\n\nPermission is hereby granted\n"
+	want := `--- LICENSE
++++ LICENSE (new)
+@@ -1,5 +1,5 @@
+ MIT License
+ 
+-Copyright (c) 2020 Acme
++Copyright (c) 2026 Acme Corp
+ 
+ Permission is hereby granted
+`
+	if got := Unified("LICENSE", "LICENSE (new)", a, b, 3); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n", 3); got != "" {
+		t.Errorf("Unified of equal texts = %q", got)
+	}
+}
+
+func TestUnified_SeparateHunksAndNoNewline(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+	want := `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
+\ No newline at end of file
++twelve
+`
+	if got := Unified("a", "b", a, b, 3); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnified_FromEmpty(t *testing.T) {
+	want := "--- /dev/null\n+++ LICENSE\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := Unified("/dev/null", "LICENSE", "", "a\nb\n", 3); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+}
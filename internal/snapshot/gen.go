@@ -0,0 +1,86 @@
+//go:build ignore
+
+// gen downloads the json/ directory of spdx/license-list-data at a tag into data/, replacing the
+// embedded snapshot. Run via go generate ./internal/snapshot.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const base = "https://raw.githubusercontent.com/spdx/license-list-data/%s/json/%s"
+
+var client = &http.Client{Timeout: 60 * time.Second}
+
+func main() {
+	tag := flag.String("tag", "main", "license-list-data git tag or branch")
+	out := flag.String("out", "data", "output directory")
+	flag.Parse()
+
+	tmp := *out + ".new"
+	if err := os.RemoveAll(tmp); err != nil {
+		log.Fatal(err)
+	}
+	var licenses struct {
+		Licenses []struct {
+			LicenseID string `json:"licenseId"`
+		} `json:"licenses"`
+	}
+	fetch(*tag, "licenses.json", tmp, &licenses)
+	for _, l := range licenses.Licenses {
+		fetch(*tag, "details/"+l.LicenseID+".json", tmp, nil)
+	}
+	var exceptions struct {
+		Exceptions []struct {
+			LicenseExceptionID string `json:"licenseExceptionId"`
+		} `json:"exceptions"`
+	}
+	fetch(*tag, "exceptions.json", tmp, &exceptions)
+	for _, e := range exceptions.Exceptions {
+		fetch(*tag, "exceptions/"+e.LicenseExceptionID+".json", tmp, nil)
+	}
+
+	if err := os.RemoveAll(*out); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Rename(tmp, *out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("snapshot: %d licenses, %d exceptions from license-list-data %s\n", len(licenses.Licenses), len(exceptions.Exceptions), *tag)
+}
+
+// fetch downloads json/<name> at tag into dir/<name>, decoding it into v when non-nil.
+func fetch(tag, name, dir string, v any) {
+	resp, err := client.Get(fmt.Sprintf(base, tag, name))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: HTTP %s", name, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	if v != nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+	}
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build release
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+)
+
+// TestSource_NotSeed guards release builds (go test -tags release ./internal/snapshot) against
+// shipping the seed: the embedded data must be the go generate output for a pinned SPDX tag.
+func TestSource_NotSeed(t *testing.T) {
+	if v, _ := Version(); v == "" || IsSeed() {
+		t.Fatalf("embedded snapshot is version %q; run go generate ./internal/snapshot", v)
+	}
+	list, err := Source().LicenseList(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Licenses) < 500 {
+		t.Errorf("embedded snapshot has %d licenses, want the full SPDX list", len(list.Licenses))
+	}
+	exList, err := Source().ExceptionList(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exList.Exceptions) < 40 {
+		t.Errorf("embedded snapshot has %d exceptions, want the full SPDX list", len(exList.Exceptions))
+	}
+	if d, err := Source().LicenseDetails(context.Background(), "Zlib"); err != nil || d.StandardLicenseTemplate == "" {
+		t.Errorf("Zlib = %v, %v; want its details with a standardLicenseTemplate", d, err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package snapshot embeds a copy of the SPDX license-list-data json/ directory (licenses.json,
+// details/, exceptions.json, exceptions/) so ligma works on first run and on air-gapped machines.
+//
+// The checked-in data is a seed with the common licenses only (licenseListVersion "seed"), not a
+// snapshot of the SPDX list; IsSeed reports it, and ligma says so wherever it serves the data. Release
+// builds replace it with the full list for a pinned SPDX tag:
+//
+//	go generate ./internal/snapshot
+//
+// go test -tags release ./internal/snapshot fails while the seed is still in place.
+package snapshot
+
+//go:generate go run gen.go -tag v3.27.0
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"sync"
+
+	"github.com/tom/ligma/internal/spdx"
+)
+
+//go:embed data/licenses.json data/exceptions.json data/details data/exceptions
+var data embed.FS
+
+// FS returns the embedded json/ directory.
+func FS() fs.FS {
+	sub, err := fs.Sub(data, "data")
+	if err != nil {
+		panic(err) // "data" is a valid, embedded path
+	}
+	return sub
+}
+
+// SeedVersion is the licenseListVersion of the checked-in seed.
+const SeedVersion = "seed"
+
+// Source returns an spdx.Source over the embedded data.
+func Source() *spdx.FSSource {
+	return &spdx.FSSource{FS: FS()}
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+	releaseDate string
+)
+
+// Version returns the SPDX license list version and release date of the embedded licenses.json.
+func Version() (listVersion, date string) {
+	versionOnce.Do(func() {
+		b, err := data.ReadFile("data/licenses.json")
+		if err != nil {
+			return
+		}
+		var v struct {
+			LicenseListVersion string `json:"licenseListVersion"`
+			ReleaseDate        string `json:"releaseDate"`
+		}
+		if json.Unmarshal(b, &v) == nil {
+			version, releaseDate = v.LicenseListVersion, v.ReleaseDate
+		}
+	})
+	return version, releaseDate
+}
+
+// IsSeed reports whether the embedded data is the checked-in seed rather than a full SPDX list.
+func IsSeed() bool {
+	v, _ := Version()
+	return v == SeedVersion
+}
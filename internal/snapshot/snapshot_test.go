@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSource_Consistent(t *testing.T) {
+	ctx := context.Background()
+	src := Source()
+	list, err := src.LicenseList(ctx)
+	if err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	if len(list.Licenses) == 0 {
+		t.Fatal("LicenseList: no licenses")
+	}
+	for _, l := range list.Licenses {
+		d, err := src.LicenseDetails(ctx, l.LicenseID)
+		if err != nil {
+			t.Errorf("LicenseDetails(%s): %v", l.LicenseID, err)
+			continue
+		}
+		if d.LicenseID != l.LicenseID || d.LicenseText == "" {
+			t.Errorf("LicenseDetails(%s) = id %q, %d bytes of text", l.LicenseID, d.LicenseID, len(d.LicenseText))
+		}
+	}
+	exList, err := src.ExceptionList(ctx)
+	if err != nil {
+		t.Fatalf("ExceptionList: %v", err)
+	}
+	for _, e := range exList.Exceptions {
+		if d, err := src.ExceptionDetails(ctx, e.LicenseExceptionID); err != nil || d.LicenseExceptionText == "" {
+			t.Errorf("ExceptionDetails(%s) = %v, %v", e.LicenseExceptionID, d, err)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	v, _ := Version()
+	list, err := Source().LicenseList(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v == "" || v != list.LicenseListVersion {
+		t.Errorf("Version() = %q, want licenses.json version %q", v, list.LicenseListVersion)
+	}
+}
@@ -2,99 +2,106 @@ package spdx
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
-	"time"
 )
 
-// DefaultListURL is the official SPDX license list (MVP hardcoded). Epic 5.2 adds config override.
+// DefaultListURL is the official SPDX license list on the main branch of license-list-data.
 const DefaultListURL = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/licenses.json"
 
-// DefaultDetailsURLTemplate is the SPDX details URL with {id} placeholder. Epic 5.2 adds config override.
+// DefaultDetailsURLTemplate is the SPDX details URL with {id} placeholder.
 const DefaultDetailsURLTemplate = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/details/{id}.json"
 
 // ErrNotFound is returned when the license details URL returns HTTP 404. get/write map this to exit 2.
 var ErrNotFound = errors.New("spdx: not found")
 
-// License holds minimal fields from SPDX licenses.json for the list. Use as-is; no normalization (project-context).
+// License is one entry of SPDX licenses.json. Use as-is; no normalization (project-context).
+// IsFsfLibre is omitted by SPDX for licenses that are not FSF Libre, hence omitempty. IsCustom is ligma's
+// own: set on the user-defined LicenseRef- licenses CustomSource adds; so is IsOrg, set on the licenses
+// of an organization registry (see RegistrySource).
 type License struct {
-	LicenseID string `json:"licenseId"`
-	Name      string `json:"name"`
+	Reference             string   `json:"reference"`
+	IsDeprecatedLicenseID bool     `json:"isDeprecatedLicenseId"`
+	DetailsURL            string   `json:"detailsUrl"`
+	ReferenceNumber       int      `json:"referenceNumber"`
+	Name                  string   `json:"name"`
+	LicenseID             string   `json:"licenseId"`
+	SeeAlso               []string `json:"seeAlso"`
+	IsOsiApproved         bool     `json:"isOsiApproved"`
+	IsFsfLibre            bool     `json:"isFsfLibre,omitempty"`
+	IsCustom              bool     `json:"isCustom,omitempty"`
+	IsOrg                 bool     `json:"isOrg,omitempty"`
 }
 
-type listResponse struct {
-	Licenses []License `json:"licenses"`
+// LicenseList is the complete SPDX licenses.json document: list version, release date and licenses.
+// Aliases is not SPDX's: an organization registry maps its own names for licenses to IDs with it.
+type LicenseList struct {
+	LicenseListVersion string            `json:"licenseListVersion"`
+	Licenses           []License         `json:"licenses"`
+	ReleaseDate        string            `json:"releaseDate"`
+	Aliases            map[string]string `json:"aliases,omitempty"`
 }
 
-// FetchLicenseList GETs listURL, parses JSON, and returns the licenses. Returns errors only; no os.Exit (internal/).
-// Uses 30s timeout (NFR-I2). On non-2xx, network error, or timeout: returns a descriptive error.
-// On 4xx/5xx the body is not parsed as JSON.
-func FetchLicenseList(ctx context.Context, listURL string) ([]License, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("spdx: new request: %w", err)
-	}
+// FetchLicenseList GETs listURL with DefaultClient (its Timeout per attempt, its retries), parses JSON,
+// and returns the license list. Returns errors only; no os.Exit (internal/). On non-2xx, network error,
+// or timeout: returns a descriptive error. On 4xx/5xx the body is not parsed as JSON.
+func FetchLicenseList(ctx context.Context, listURL string) (*LicenseList, error) {
+	list, _, err := fetchLicenseList(ctx, DefaultClient, []string{listURL}, Validators{})
+	return list, err
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+func fetchLicenseList(ctx context.Context, c *Client, listURLs []string, prev Validators) (*LicenseList, Validators, error) {
+	var list LicenseList
+	next, err := c.getJSONFrom(ctx, listURLs, "list", false, prev, &list)
 	if err != nil {
-		return nil, fmt.Errorf("spdx: fetch: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		_, _ = io.Copy(io.Discard, resp.Body)
-		return nil, fmt.Errorf("spdx: list fetch failed: HTTP %s", resp.Status)
-	}
-
-	var list listResponse
-	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
-		return nil, fmt.Errorf("spdx: invalid JSON: %w", err)
+		return nil, next, err
 	}
-	return list.Licenses, nil
+	return &list, next, nil
 }
 
-type detailsResponse struct {
-	LicenseText string `json:"licenseText"`
+// LicenseDetails holds the fields of SPDX details/{id}.json that ligma uses. StandardLicenseTemplate
+// carries the <<var;...>> / <<beginOptional>> markup used to fill copyright placeholders (see Fill).
+// StandardLicenseHeader is the "how to apply" notice some licenses (Apache-2.0, GPL) ask to put in source files.
+type LicenseDetails struct {
+	LicenseID                     string   `json:"licenseId"`
+	Name                          string   `json:"name"`
+	LicenseText                   string   `json:"licenseText"`
+	StandardLicenseTemplate       string   `json:"standardLicenseTemplate,omitempty"`
+	StandardLicenseHeader         string   `json:"standardLicenseHeader,omitempty"`
+	StandardLicenseHeaderTemplate string   `json:"standardLicenseHeaderTemplate,omitempty"`
+	IsDeprecatedLicenseID         bool     `json:"isDeprecatedLicenseId"`
+	IsOsiApproved                 bool     `json:"isOsiApproved"`
+	IsFsfLibre                    bool     `json:"isFsfLibre,omitempty"`
+	SeeAlso                       []string `json:"seeAlso,omitempty"`
 }
 
-// FetchLicenseDetails GETs the details URL (template with {id} replaced by id as-is), parses JSON,
-// and returns the licenseText. Uses 30s timeout (NFR-I2). On 404 returns ErrNotFound (get→exit 2);
+// FetchLicenseDetails GETs the details URL (template with {id} replaced by id as-is) with DefaultClient,
+// parses JSON, and returns the license details. On 404 returns ErrNotFound (get→exit 2);
 // on other 4xx/5xx, network, timeout, invalid JSON, or missing licenseText returns an error (get→exit 3).
 // No os.Exit in internal/.
-func FetchLicenseDetails(ctx context.Context, detailsURLTemplate, id string) (string, error) {
-	url := strings.ReplaceAll(detailsURLTemplate, "{id}", id)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return "", fmt.Errorf("spdx: new request: %w", err)
-	}
+func FetchLicenseDetails(ctx context.Context, detailsURLTemplate, id string) (*LicenseDetails, error) {
+	d, _, err := fetchLicenseDetails(ctx, DefaultClient, []string{detailsURLTemplate}, id, Validators{})
+	return d, err
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+func fetchLicenseDetails(ctx context.Context, c *Client, detailsURLTemplates []string, id string, prev Validators) (*LicenseDetails, Validators, error) {
+	var d LicenseDetails
+	next, err := c.getJSONFrom(ctx, expandID(detailsURLTemplates, id), "details", true, prev, &d)
 	if err != nil {
-		return "", fmt.Errorf("spdx: fetch: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		_, _ = io.Copy(io.Discard, resp.Body)
-		return "", ErrNotFound
+		return nil, next, err
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		_, _ = io.Copy(io.Discard, resp.Body)
-		return "", fmt.Errorf("spdx: details fetch failed: HTTP %s", resp.Status)
+	if d.LicenseText == "" {
+		return nil, next, fmt.Errorf("spdx: missing licenseText")
 	}
+	return &d, next, nil
+}
 
-	var d detailsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
-		return "", fmt.Errorf("spdx: invalid JSON: %w", err)
-	}
-	if d.LicenseText == "" {
-		return "", fmt.Errorf("spdx: missing licenseText")
+// expandID replaces {id} with id (as-is) in each URL template.
+func expandID(templates []string, id string) []string {
+	urls := make([]string, len(templates))
+	for i, t := range templates {
+		urls[i] = strings.ReplaceAll(t, "{id}", id)
 	}
-	return d.LicenseText, nil
+	return urls
 }
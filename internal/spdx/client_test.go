@@ -25,14 +25,50 @@ func TestFetchLicenseList_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("FetchLicenseList: %v", err)
 	}
-	if len(list) != 2 {
-		t.Errorf("len(list) = %d, want 2", len(list))
+	if list.LicenseListVersion != "3.0" {
+		t.Errorf("LicenseListVersion = %q, want 3.0", list.LicenseListVersion)
 	}
-	if list[0].LicenseID != "MIT" || list[0].Name != "MIT License" {
-		t.Errorf("list[0] = %+v", list[0])
+	if len(list.Licenses) != 2 {
+		t.Errorf("len(list.Licenses) = %d, want 2", len(list.Licenses))
 	}
-	if list[1].LicenseID != "Apache-2.0" || list[1].Name != "Apache License 2.0" {
-		t.Errorf("list[1] = %+v", list[1])
+	if list.Licenses[0].LicenseID != "MIT" || list.Licenses[0].Name != "MIT License" {
+		t.Errorf("list.Licenses[0] = %+v", list.Licenses[0])
+	}
+	if list.Licenses[1].LicenseID != "Apache-2.0" || list.Licenses[1].Name != "Apache License 2.0" {
+		t.Errorf("list.Licenses[1] = %+v", list.Licenses[1])
+	}
+}
+
+const fullJSON = `{"licenseListVersion":"3.24","licenses":[{"reference":"https://spdx.org/licenses/MIT.html","isDeprecatedLicenseId":false,"detailsUrl":"https://spdx.org/licenses/MIT.json","referenceNumber":223,"name":"MIT License","licenseId":"MIT","seeAlso":["https://opensource.org/license/mit/"],"isOsiApproved":true,"isFsfLibre":true},{"reference":"https://spdx.org/licenses/GPL-2.0.html","isDeprecatedLicenseId":true,"detailsUrl":"https://spdx.org/licenses/GPL-2.0.json","referenceNumber":101,"name":"GNU General Public License v2.0 only","licenseId":"GPL-2.0","seeAlso":[],"isOsiApproved":true}],"releaseDate":"2024-05-22"}`
+
+func TestFetchLicenseList_FullMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(fullJSON))
+	}))
+	defer srv.Close()
+
+	list, err := FetchLicenseList(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("FetchLicenseList: %v", err)
+	}
+	if list.LicenseListVersion != "3.24" || list.ReleaseDate != "2024-05-22" {
+		t.Errorf("version/releaseDate = %q/%q", list.LicenseListVersion, list.ReleaseDate)
+	}
+	mit := list.Licenses[0]
+	if !mit.IsOsiApproved || !mit.IsFsfLibre || mit.IsDeprecatedLicenseID {
+		t.Errorf("MIT flags = %+v", mit)
+	}
+	if mit.Reference != "https://spdx.org/licenses/MIT.html" || mit.DetailsURL != "https://spdx.org/licenses/MIT.json" || mit.ReferenceNumber != 223 {
+		t.Errorf("MIT references = %+v", mit)
+	}
+	if len(mit.SeeAlso) != 1 || mit.SeeAlso[0] != "https://opensource.org/license/mit/" {
+		t.Errorf("MIT seeAlso = %v", mit.SeeAlso)
+	}
+	gpl := list.Licenses[1]
+	if !gpl.IsDeprecatedLicenseID || gpl.IsFsfLibre {
+		t.Errorf("GPL-2.0 flags = %+v", gpl)
 	}
 }
 
@@ -71,6 +107,7 @@ func TestFetchLicenseList_InvalidJSON(t *testing.T) {
 }
 
 func TestFetchLicenseList_5xx(t *testing.T) {
+	fastRetries(t)
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}))
@@ -116,12 +153,15 @@ func TestFetchLicenseDetails_Success(t *testing.T) {
 
 	template := srv.URL + "/{id}.json"
 	ctx := context.Background()
-	text, err := FetchLicenseDetails(ctx, template, "MIT")
+	d, err := FetchLicenseDetails(ctx, template, "MIT")
 	if err != nil {
 		t.Fatalf("FetchLicenseDetails: %v", err)
 	}
-	if !strings.Contains(text, "Permission is hereby granted") {
-		t.Errorf("licenseText = %q", text)
+	if !strings.Contains(d.LicenseText, "Permission is hereby granted") {
+		t.Errorf("licenseText = %q", d.LicenseText)
+	}
+	if d.LicenseID != "MIT" || d.Name != "MIT License" {
+		t.Errorf("details = %+v", d)
 	}
 }
 
@@ -143,6 +183,7 @@ func TestFetchLicenseDetails_404_ReturnsErrNotFound(t *testing.T) {
 }
 
 func TestFetchLicenseDetails_5xx_ReturnsError(t *testing.T) {
+	fastRetries(t)
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}))
@@ -0,0 +1,53 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotModified is returned by conditional fetches when the server answers 304 Not Modified: the
+// caller's copy, identified by the validators it sent, is still current.
+var ErrNotModified = errors.New("spdx: not modified")
+
+// Validators are the HTTP cache validators (ETag, Last-Modified) of a fetched document.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsZero reports whether no validator is set.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// ConditionalSource is a Source that can revalidate a stored copy. Each *If method sends prev (when
+// non-zero) with the request and returns the document with its new validators, or ErrNotModified
+// when the stored copy is current. The cache package uses it to refresh expired entries without a
+// download.
+type ConditionalSource interface {
+	Source
+	LicenseListIf(ctx context.Context, prev Validators) (*LicenseList, Validators, error)
+	LicenseDetailsIf(ctx context.Context, id string, prev Validators) (*LicenseDetails, Validators, error)
+	ExceptionListIf(ctx context.Context, prev Validators) (*ExceptionList, Validators, error)
+	ExceptionDetailsIf(ctx context.Context, id string, prev Validators) (*ExceptionDetails, Validators, error)
+}
+
+// LicenseListIf implements ConditionalSource.
+func (s *HTTPSource) LicenseListIf(ctx context.Context, prev Validators) (*LicenseList, Validators, error) {
+	return fetchLicenseList(ctx, s.client(), urls(s.ListURL, DefaultListURL, s.ListMirrors), prev)
+}
+
+// LicenseDetailsIf implements ConditionalSource.
+func (s *HTTPSource) LicenseDetailsIf(ctx context.Context, id string, prev Validators) (*LicenseDetails, Validators, error) {
+	return fetchLicenseDetails(ctx, s.client(), urls(s.DetailsURLTemplate, DefaultDetailsURLTemplate, s.DetailsTemplateMirrors), id, prev)
+}
+
+// ExceptionListIf implements ConditionalSource.
+func (s *HTTPSource) ExceptionListIf(ctx context.Context, prev Validators) (*ExceptionList, Validators, error) {
+	return fetchExceptionList(ctx, s.client(), urls(s.ExceptionsURL, DefaultExceptionsURL, s.ExceptionsMirrors), prev)
+}
+
+// ExceptionDetailsIf implements ConditionalSource.
+func (s *HTTPSource) ExceptionDetailsIf(ctx context.Context, id string, prev Validators) (*ExceptionDetails, Validators, error) {
+	return fetchExceptionDetails(ctx, s.client(), urls(s.ExceptionDetailsURLTemplate, DefaultExceptionDetailsURLTemplate, s.ExceptionDetailsTemplateMirrors), id, prev)
+}
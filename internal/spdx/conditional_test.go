@@ -0,0 +1,45 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSource_Conditional(t *testing.T) {
+	var gotINM, gotIMS string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotINM, gotIMS = r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")
+		if gotINM == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 01 Jan 2025 00:00:00 GMT")
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer srv.Close()
+	src := &HTTPSource{ListURL: srv.URL}
+	ctx := context.Background()
+
+	list, v, err := src.LicenseListIf(ctx, Validators{})
+	if err != nil || list == nil {
+		t.Fatalf("LicenseListIf = %v, %v", list, err)
+	}
+	if gotINM != "" || gotIMS != "" {
+		t.Errorf("unconditional request sent If-None-Match %q, If-Modified-Since %q", gotINM, gotIMS)
+	}
+	if v.ETag != `"v1"` || v.LastModified != "Wed, 01 Jan 2025 00:00:00 GMT" {
+		t.Errorf("validators = %+v", v)
+	}
+
+	list, v2, err := src.LicenseListIf(ctx, v)
+	if !errors.Is(err, ErrNotModified) || list != nil {
+		t.Fatalf("revalidation = %v, %v; want ErrNotModified", list, err)
+	}
+	if v2 != v || gotIMS != v.LastModified {
+		t.Errorf("revalidation validators = %+v, If-Modified-Since %q", v2, gotIMS)
+	}
+}
@@ -0,0 +1,160 @@
+package spdx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CustomPrefix starts the ID of every user-defined license, as SPDX reserves it for licenses outside
+// its list.
+const CustomPrefix = "LicenseRef-"
+
+// CustomSource serves user-defined LicenseRef- licenses from Dirs on top of Upstream. A license is a
+// <id>.json file in the shape of SPDX license details (name, licenseText, standardLicenseTemplate,
+// seeAlso, ...), a <id>.txt file with its text, or both (the .txt supplies a missing licenseText);
+// the file name is the ID. The first of Dirs that has an ID wins, and a custom license wins over an
+// Upstream license with its ID (an organization registry's). The license list is Upstream's plus the
+// custom licenses, marked IsCustom; exceptions are Upstream's alone.
+type CustomSource struct {
+	Upstream Source
+	Dirs     []string
+	// Warnf, if set, reports the Upstream licenses a custom license replaces.
+	Warnf func(format string, args ...any)
+}
+
+// LicenseList implements Source: Upstream's list with the custom licenses appended in ID order.
+func (s *CustomSource) LicenseList(ctx context.Context) (*LicenseList, error) {
+	list, err := s.Upstream.LicenseList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := s.Licenses()
+	if err != nil {
+		return nil, err
+	}
+	if len(custom) == 0 {
+		return list, nil
+	}
+	ids := make(map[string]bool, len(custom))
+	for _, l := range custom {
+		ids[l.LicenseID] = true
+	}
+	merged := *list
+	merged.Licenses = nil
+	for _, l := range list.Licenses {
+		if ids[l.LicenseID] {
+			if s.Warnf != nil {
+				s.Warnf("custom license %s replaces the organization registry's", l.LicenseID)
+			}
+			continue
+		}
+		merged.Licenses = append(merged.Licenses, l)
+	}
+	merged.Licenses = append(merged.Licenses, custom...)
+	return &merged, nil
+}
+
+// Licenses returns the custom licenses of s in ID order.
+func (s *CustomSource) Licenses() ([]License, error) {
+	seen := make(map[string]bool)
+	var licenses []License
+	for _, dir := range s.Dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("spdx: custom licenses: %w", err)
+		}
+		for _, e := range entries {
+			name := e.Name()
+			id := strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".txt")
+			if e.IsDir() || id == name || !strings.HasPrefix(id, CustomPrefix) || seen[id] {
+				continue
+			}
+			seen[id] = true
+			d, err := readCustom(dir, id)
+			if err != nil {
+				return nil, err
+			}
+			licenses = append(licenses, License{
+				LicenseID:     d.LicenseID,
+				Name:          d.Name,
+				SeeAlso:       d.SeeAlso,
+				IsOsiApproved: d.IsOsiApproved,
+				IsFsfLibre:    d.IsFsfLibre,
+				IsCustom:      true,
+			})
+		}
+	}
+	sort.Slice(licenses, func(i, j int) bool { return licenses[i].LicenseID < licenses[j].LicenseID })
+	return licenses, nil
+}
+
+// LicenseDetails implements Source: a LicenseRef- ID from the first of Dirs that has it, anything
+// else (an organization registry's LicenseRef- IDs included) from Upstream.
+func (s *CustomSource) LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error) {
+	if !strings.HasPrefix(id, CustomPrefix) || !validID(id) {
+		return s.Upstream.LicenseDetails(ctx, id)
+	}
+	for _, dir := range s.Dirs {
+		d, err := readCustom(dir, id)
+		if !errors.Is(err, ErrNotFound) {
+			return d, err
+		}
+	}
+	return s.Upstream.LicenseDetails(ctx, id)
+}
+
+// ExceptionList implements Source.
+func (s *CustomSource) ExceptionList(ctx context.Context) (*ExceptionList, error) {
+	return s.Upstream.ExceptionList(ctx)
+}
+
+// ExceptionDetails implements Source.
+func (s *CustomSource) ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error) {
+	return s.Upstream.ExceptionDetails(ctx, id)
+}
+
+// readCustom reads the license id from dir: <id>.json and/or <id>.txt; ErrNotFound when neither exists.
+func readCustom(dir, id string) (*LicenseDetails, error) {
+	var d LicenseDetails
+	b, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	hasJSON := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("spdx: custom license %s: %w", id, err)
+	}
+	if hasJSON {
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, fmt.Errorf("spdx: custom license %s: invalid JSON in %s: %w", id, filepath.Join(dir, id+".json"), err)
+		}
+		if d.LicenseID != "" && d.LicenseID != id {
+			return nil, fmt.Errorf("spdx: custom license %s: licenseId %q does not match the file name", id, d.LicenseID)
+		}
+	}
+	if d.LicenseText == "" {
+		b, err := os.ReadFile(filepath.Join(dir, id+".txt"))
+		switch {
+		case err == nil:
+			d.LicenseText = string(b)
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("spdx: custom license %s: %w", id, err)
+		case hasJSON:
+			return nil, fmt.Errorf("spdx: custom license %s: no licenseText in %s and no %s.txt", id, filepath.Join(dir, id+".json"), id)
+		default:
+			return nil, ErrNotFound
+		}
+	}
+	d.LicenseID = id
+	if d.Name == "" {
+		d.Name = id
+	}
+	return &d, nil
+}
@@ -0,0 +1,112 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCustomSource(t *testing.T) {
+	project, user := t.TempDir(), t.TempDir()
+	writeFiles(t, project, map[string]string{
+		"LicenseRef-Acme-EULA.json": `{"name":"Acme EULA","seeAlso":["https://acme.example/eula"]}`,
+		"LicenseRef-Acme-EULA.txt":  "Copyright <year> <copyright holders>\n",
+		"README.md":                 "not a license",
+	})
+	writeFiles(t, user, map[string]string{
+		"LicenseRef-Acme-EULA.txt":    "older text",
+		"LicenseRef-Acme-Source.json": `{"licenseId":"LicenseRef-Acme-Source","name":"Acme Source-Available","licenseText":"source text"}`,
+	})
+	s := &CustomSource{
+		Upstream: &MemorySource{
+			Licenses: &LicenseList{LicenseListVersion: "3.27", Licenses: []License{{LicenseID: "MIT"}}},
+			LicenseDetailsByID: map[string]*LicenseDetails{
+				"MIT":            {LicenseID: "MIT", LicenseText: "MIT text"},
+				"LicenseRef-Org": {LicenseID: "LicenseRef-Org", LicenseText: "org text"},
+			},
+		},
+		Dirs: []string{project, user},
+	}
+	ctx := context.Background()
+
+	list, err := s.LicenseList(ctx)
+	if err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	if len(list.Licenses) != 3 || list.LicenseListVersion != "3.27" {
+		t.Fatalf("LicenseList = %+v", list)
+	}
+	if l := list.Licenses[1]; l.LicenseID != "LicenseRef-Acme-EULA" || l.Name != "Acme EULA" || !l.IsCustom || len(l.SeeAlso) != 1 {
+		t.Errorf("custom entry = %+v", l)
+	}
+
+	d, err := s.LicenseDetails(ctx, "LicenseRef-Acme-EULA")
+	if err != nil || d.LicenseText != "Copyright <year> <copyright holders>\n" {
+		t.Errorf("details from the first dir = %+v, %v", d, err)
+	}
+	if got := Fill(d, Values{Holder: "Acme", Year: "2026"}); got != "Copyright 2026 Acme\n" {
+		t.Errorf("Fill = %q", got)
+	}
+	if d, err := s.LicenseDetails(ctx, "LicenseRef-Acme-Source"); err != nil || d.LicenseText != "source text" {
+		t.Errorf("details from the second dir = %+v, %v", d, err)
+	}
+	if d, err := s.LicenseDetails(ctx, "MIT"); err != nil || d.LicenseText != "MIT text" {
+		t.Errorf("upstream details = %+v, %v", d, err)
+	}
+	if d, err := s.LicenseDetails(ctx, "LicenseRef-Org"); err != nil || d.LicenseText != "org text" {
+		t.Errorf("upstream LicenseRef- details = %+v, %v", d, err)
+	}
+	if _, err := s.LicenseDetails(ctx, "LicenseRef-Nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown LicenseRef: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCustomSource_BadEntries(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"invalid JSON": {"LicenseRef-X.json": `{`},
+		"no text":      {"LicenseRef-X.json": `{"name":"X"}`},
+		"ID mismatch":  {"LicenseRef-X.json": `{"licenseId":"LicenseRef-Y","licenseText":"t"}`},
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		s := &CustomSource{Upstream: &MemorySource{}, Dirs: []string{dir}}
+		if _, err := s.LicenseDetails(context.Background(), "LicenseRef-X"); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected an error, got %v", name, err)
+		}
+		if _, err := s.Licenses(); err == nil {
+			t.Errorf("%s: Licenses: expected an error", name)
+		}
+	}
+}
+
+func TestCustomSource_ReplacesUpstreamEntry(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"LicenseRef-Acme.txt": "local text"})
+	var warnings []string
+	s := &CustomSource{
+		Upstream: &MemorySource{Licenses: &LicenseList{Licenses: []License{{LicenseID: "MIT"}, {LicenseID: "LicenseRef-Acme", IsOrg: true}}}},
+		Dirs:     []string{dir},
+		Warnf:    func(format string, args ...any) { warnings = append(warnings, format) },
+	}
+	list, err := s.LicenseList(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Licenses) != 2 || !list.Licenses[1].IsCustom || list.Licenses[1].IsOrg {
+		t.Errorf("licenses = %+v, want MIT and the custom LicenseRef-Acme", list.Licenses)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
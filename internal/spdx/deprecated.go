@@ -0,0 +1,43 @@
+package spdx
+
+// replacements maps deprecated SPDX IDs to what should be used instead: an ID, or an expression for
+// the old "<license>-with-<exception>" IDs. SPDX flags deprecated IDs (isDeprecatedLicenseId) but does
+// not name their successors, so this list is maintained by hand from the SPDX release notes.
+var replacements = map[string]string{
+	"AGPL-1.0":                         "AGPL-1.0-only",
+	"AGPL-3.0":                         "AGPL-3.0-only",
+	"BSD-2-Clause-FreeBSD":             "BSD-2-Clause",
+	"BSD-2-Clause-NetBSD":              "BSD-2-Clause",
+	"GFDL-1.1":                         "GFDL-1.1-only",
+	"GFDL-1.2":                         "GFDL-1.2-only",
+	"GFDL-1.3":                         "GFDL-1.3-only",
+	"GPL-1.0":                          "GPL-1.0-only",
+	"GPL-1.0+":                         "GPL-1.0-or-later",
+	"GPL-2.0":                          "GPL-2.0-only",
+	"GPL-2.0+":                         "GPL-2.0-or-later",
+	"GPL-2.0-with-autoconf-exception":  "GPL-2.0-only WITH Autoconf-exception-2.0",
+	"GPL-2.0-with-bison-exception":     "GPL-2.0-or-later WITH Bison-exception-2.2",
+	"GPL-2.0-with-classpath-exception": "GPL-2.0-only WITH Classpath-exception-2.0",
+	"GPL-2.0-with-font-exception":      "GPL-2.0-only WITH Font-exception-2.0",
+	"GPL-2.0-with-GCC-exception":       "GPL-2.0-or-later WITH GCC-exception-2.0",
+	"GPL-3.0":                          "GPL-3.0-only",
+	"GPL-3.0+":                         "GPL-3.0-or-later",
+	"GPL-3.0-with-autoconf-exception":  "GPL-3.0-only WITH Autoconf-exception-3.0",
+	"GPL-3.0-with-GCC-exception":       "GPL-3.0-only WITH GCC-exception-3.1",
+	"LGPL-2.0":                         "LGPL-2.0-only",
+	"LGPL-2.0+":                        "LGPL-2.0-or-later",
+	"LGPL-2.1":                         "LGPL-2.1-only",
+	"LGPL-2.1+":                        "LGPL-2.1-or-later",
+	"LGPL-3.0":                         "LGPL-3.0-only",
+	"LGPL-3.0+":                        "LGPL-3.0-or-later",
+	"StandardML-NJ":                    "SMLNJ",
+	"eCos-2.0":                         "GPL-2.0-or-later WITH eCos-exception-2.0",
+	"wxWindows":                        "LGPL-2.0-or-later WITH WxWindows-exception-3.1",
+}
+
+// Deprecation reports whether id is a deprecated SPDX ID, either flagged so by SPDX (flagged is its
+// isDeprecatedLicenseId) or known to the replacement map, and what replaces it ("" when nothing does).
+func Deprecation(id string, flagged bool) (replacement string, deprecated bool) {
+	replacement, known := replacements[id]
+	return replacement, flagged || known
+}
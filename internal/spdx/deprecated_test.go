@@ -0,0 +1,24 @@
+package spdx
+
+import "testing"
+
+func TestDeprecation(t *testing.T) {
+	for _, tc := range []struct {
+		id          string
+		flagged     bool
+		replacement string
+		deprecated  bool
+	}{
+		{"GPL-2.0", false, "GPL-2.0-only", true},
+		{"GPL-2.0+", true, "GPL-2.0-or-later", true},
+		{"GPL-2.0-with-classpath-exception", true, "GPL-2.0-only WITH Classpath-exception-2.0", true},
+		{"Nunit", true, "", true},
+		{"GPL-2.0-only", false, "", false},
+		{"MIT", false, "", false},
+	} {
+		replacement, deprecated := Deprecation(tc.id, tc.flagged)
+		if replacement != tc.replacement || deprecated != tc.deprecated {
+			t.Errorf("Deprecation(%q, %v) = %q, %v; want %q, %v", tc.id, tc.flagged, replacement, deprecated, tc.replacement, tc.deprecated)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package spdx
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultExceptionsURL is the official SPDX license exceptions list.
+const DefaultExceptionsURL = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/exceptions.json"
+
+// DefaultExceptionDetailsURLTemplate is the SPDX exception details URL with {id} placeholder.
+const DefaultExceptionDetailsURLTemplate = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/exceptions/{id}.json"
+
+// Exception is one entry of SPDX exceptions.json (e.g. Classpath-exception-2.0, LLVM-exception).
+type Exception struct {
+	Reference             string   `json:"reference"`
+	IsDeprecatedLicenseID bool     `json:"isDeprecatedLicenseId"`
+	DetailsURL            string   `json:"detailsUrl"`
+	ReferenceNumber       int      `json:"referenceNumber"`
+	Name                  string   `json:"name"`
+	LicenseExceptionID    string   `json:"licenseExceptionId"`
+	SeeAlso               []string `json:"seeAlso"`
+}
+
+// ExceptionList is the complete SPDX exceptions.json document.
+type ExceptionList struct {
+	LicenseListVersion string      `json:"licenseListVersion"`
+	Exceptions         []Exception `json:"exceptions"`
+	ReleaseDate        string      `json:"releaseDate"`
+}
+
+// FetchExceptionList GETs exceptionsURL and returns the exception list. Same timeout and error
+// behavior as FetchLicenseList.
+func FetchExceptionList(ctx context.Context, exceptionsURL string) (*ExceptionList, error) {
+	list, _, err := fetchExceptionList(ctx, DefaultClient, []string{exceptionsURL}, Validators{})
+	return list, err
+}
+
+func fetchExceptionList(ctx context.Context, c *Client, exceptionsURLs []string, prev Validators) (*ExceptionList, Validators, error) {
+	var list ExceptionList
+	next, err := c.getJSONFrom(ctx, exceptionsURLs, "exceptions", false, prev, &list)
+	if err != nil {
+		return nil, next, err
+	}
+	return &list, next, nil
+}
+
+// ExceptionDetails holds the fields of SPDX exceptions/{id}.json that ligma uses.
+type ExceptionDetails struct {
+	LicenseExceptionID       string `json:"licenseExceptionId"`
+	Name                     string `json:"name"`
+	LicenseExceptionText     string `json:"licenseExceptionText"`
+	LicenseExceptionTemplate string `json:"licenseExceptionTemplate,omitempty"`
+	IsDeprecatedLicenseID    bool   `json:"isDeprecatedLicenseId"`
+}
+
+// FetchExceptionDetails GETs the exception details URL (template with {id} replaced by id as-is) and
+// returns the exception details. On 404 returns ErrNotFound; otherwise same errors as FetchLicenseDetails.
+func FetchExceptionDetails(ctx context.Context, detailsURLTemplate, id string) (*ExceptionDetails, error) {
+	d, _, err := fetchExceptionDetails(ctx, DefaultClient, []string{detailsURLTemplate}, id, Validators{})
+	return d, err
+}
+
+func fetchExceptionDetails(ctx context.Context, c *Client, detailsURLTemplates []string, id string, prev Validators) (*ExceptionDetails, Validators, error) {
+	var d ExceptionDetails
+	next, err := c.getJSONFrom(ctx, expandID(detailsURLTemplates, id), "exception details", true, prev, &d)
+	if err != nil {
+		return nil, next, err
+	}
+	if d.LicenseExceptionText == "" {
+		return nil, next, fmt.Errorf("spdx: missing licenseExceptionText")
+	}
+	return &d, next, nil
+}
@@ -0,0 +1,96 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const goodExceptionsJSON = `{"licenseListVersion":"3.24","exceptions":[{"reference":"./LLVM-exception.json","isDeprecatedLicenseId":false,"detailsUrl":"./LLVM-exception.html","referenceNumber":33,"name":"LLVM Exception","licenseExceptionId":"LLVM-exception","seeAlso":["http://llvm.org/foundation/relicensing/LICENSE.txt"]}],"releaseDate":"2024-05-22"}`
+
+func TestFetchExceptionList_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(goodExceptionsJSON))
+	}))
+	defer srv.Close()
+
+	list, err := FetchExceptionList(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("FetchExceptionList: %v", err)
+	}
+	if list.LicenseListVersion != "3.24" || len(list.Exceptions) != 1 {
+		t.Fatalf("list = %+v", list)
+	}
+	if e := list.Exceptions[0]; e.LicenseExceptionID != "LLVM-exception" || e.Name != "LLVM Exception" || len(e.SeeAlso) != 1 {
+		t.Errorf("exception = %+v", e)
+	}
+}
+
+func TestFetchExceptionList_5xx(t *testing.T) {
+	fastRetries(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := FetchExceptionList(context.Background(), srv.URL)
+	if err == nil {
+		t.Fatal("FetchExceptionList: expected error for 502")
+	}
+	if err.Error() != "spdx: exceptions fetch failed: HTTP 502 Bad Gateway" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestFetchExceptionDetails_Success(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"licenseExceptionId":"LLVM-exception","licenseExceptionText":"---- LLVM Exceptions to the Apache 2.0 License ----"}`))
+	}))
+	defer srv.Close()
+
+	d, err := FetchExceptionDetails(context.Background(), srv.URL+"/exceptions/{id}.json", "LLVM-exception")
+	if err != nil {
+		t.Fatalf("FetchExceptionDetails: %v", err)
+	}
+	if gotPath != "/exceptions/LLVM-exception.json" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if d.LicenseExceptionID != "LLVM-exception" || !strings.Contains(d.LicenseExceptionText, "LLVM Exceptions") {
+		t.Errorf("details = %+v", d)
+	}
+}
+
+func TestFetchExceptionDetails_404_ReturnsErrNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := FetchExceptionDetails(context.Background(), srv.URL+"/{id}.json", "NoSuch")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestFetchExceptionDetails_MissingText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"licenseExceptionId":"X"}`))
+	}))
+	defer srv.Close()
+
+	_, err := FetchExceptionDetails(context.Background(), srv.URL+"/{id}.json", "X")
+	if err == nil {
+		t.Fatal("FetchExceptionDetails: expected error for missing licenseExceptionText")
+	}
+}
@@ -0,0 +1,204 @@
+// Package expression parses and validates SPDX license expressions (SPDX spec Annex D), e.g.
+// "MIT OR Apache-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", "(MIT AND BSD-3-Clause) OR LicenseRef-foo".
+// No os.Exit and no I/O: callers supply the known license and exception IDs.
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Operator is a binary expression operator.
+type Operator int
+
+const (
+	// And is the AND operator (both licenses apply).
+	And Operator = iota
+	// Or is the OR operator (choice of license).
+	Or
+)
+
+func (o Operator) String() string {
+	if o == And {
+		return "AND"
+	}
+	return "OR"
+}
+
+// Expr is a node of the parsed expression: *License, *With or *Binary.
+type Expr interface {
+	// String returns the expression in canonical form (upper-case operators, minimal parentheses).
+	String() string
+}
+
+// License is a license leaf: an SPDX license ID or a LicenseRef-, optionally with "+" (or later).
+type License struct {
+	ID          string // as written, e.g. MIT or LicenseRef-foo
+	DocumentRef string // "DocumentRef-x" for DocumentRef-x:LicenseRef-y, else empty
+	OrLater     bool   // trailing "+"
+	Pos         int    // 0-based byte offset in the input
+}
+
+func (l *License) String() string {
+	s := l.ID
+	if l.DocumentRef != "" {
+		s = l.DocumentRef + ":" + s
+	}
+	if l.OrLater {
+		s += "+"
+	}
+	return s
+}
+
+// IsRef reports whether the leaf is a user-defined LicenseRef- (possibly under a DocumentRef-)
+// rather than an SPDX list ID.
+func (l *License) IsRef() bool {
+	return l.DocumentRef != "" || strings.HasPrefix(l.ID, "LicenseRef-")
+}
+
+// With is a license with an exception: "<license> WITH <exception>".
+type With struct {
+	License      *License
+	Exception    string
+	ExceptionPos int // 0-based byte offset of the exception ID in the input
+}
+
+func (w *With) String() string {
+	return w.License.String() + " WITH " + w.Exception
+}
+
+// Binary is "<left> AND <right>" or "<left> OR <right>".
+type Binary struct {
+	Op          Operator
+	Left, Right Expr
+}
+
+func (b *Binary) String() string {
+	return operand(b.Left, b.Op) + " " + b.Op.String() + " " + operand(b.Right, b.Op)
+}
+
+// operand parenthesizes e when it binds more loosely than parent (OR inside AND).
+func operand(e Expr, parent Operator) string {
+	if b, ok := e.(*Binary); ok && parent == And && b.Op == Or {
+		return "(" + b.String() + ")"
+	}
+	return e.String()
+}
+
+// Term is one license of an expression together with the exception attached to it, if any.
+type Term struct {
+	License      *License
+	Exception    string
+	ExceptionPos int
+}
+
+// Terms returns the license terms of e in left-to-right order.
+func Terms(e Expr) []Term {
+	var out []Term
+	var walk func(Expr)
+	walk = func(e Expr) {
+		switch n := e.(type) {
+		case *License:
+			out = append(out, Term{License: n})
+		case *With:
+			out = append(out, Term{License: n.License, Exception: n.Exception, ExceptionPos: n.ExceptionPos})
+		case *Binary:
+			walk(n.Left)
+			walk(n.Right)
+		}
+	}
+	walk(e)
+	return out
+}
+
+// SyntaxError is returned by Parse for malformed input. Pos is the 0-based byte offset of the problem.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// UnknownIDError is returned by Validate for an ID missing from the known licenses or exceptions.
+type UnknownIDError struct {
+	ID        string
+	Pos       int
+	Exception bool // the ID is in exception position (after WITH)
+	Ref       bool // the ID is a LicenseRef-, which may be defined outside the known licenses
+}
+
+func (e *UnknownIDError) Error() string {
+	kind := "license"
+	switch {
+	case e.Exception:
+		kind = "exception"
+	case e.Ref:
+		kind = "license reference"
+	}
+	return fmt.Sprintf("column %d: unknown %s ID %q", e.Pos+1, kind, e.ID)
+}
+
+// Validate checks every license ID in e against licenses and every exception against exceptions,
+// ignoring case as SPDX IDs are matched, and rewrites the IDs of e to their spelling there (mit →
+// MIT). A LicenseRef- leaf is checked against licenses too, which then lists the LicenseRef- IDs the
+// caller knows of, and reported with Ref set; a DocumentRef- leaf refers to another document and is
+// always accepted. Returns nil, or one *UnknownIDError per unknown ID (joined with errors.Join, in
+// input order).
+func Validate(e Expr, licenses, exceptions map[string]bool) error {
+	licenseIDs, exceptionIDs := foldIDs(licenses), foldIDs(exceptions)
+	var errs []error
+	var walk func(Expr)
+	walk = func(e Expr) {
+		switch n := e.(type) {
+		case *License:
+			if n.DocumentRef != "" {
+				return
+			}
+			if id, ok := licenseIDs[strings.ToLower(n.ID)]; ok {
+				n.ID = id
+			} else {
+				errs = append(errs, &UnknownIDError{ID: n.ID, Pos: n.Pos, Ref: n.IsRef()})
+			}
+		case *With:
+			walk(n.License)
+			if id, ok := exceptionIDs[strings.ToLower(n.Exception)]; ok {
+				n.Exception = id
+			} else {
+				errs = append(errs, &UnknownIDError{ID: n.Exception, Pos: n.ExceptionPos, Exception: true})
+			}
+		case *Binary:
+			walk(n.Left)
+			walk(n.Right)
+		}
+	}
+	walk(e)
+	return errors.Join(errs...)
+}
+
+// foldIDs maps the lower-case form of each ID in ids to the ID.
+func foldIDs(ids map[string]bool) map[string]string {
+	folded := make(map[string]string, len(ids))
+	for id, ok := range ids {
+		if ok {
+			folded[strings.ToLower(id)] = id
+		}
+	}
+	return folded
+}
+
+// Caret returns input with a second line pointing at pos, for position-aware error output:
+//
+//	MIT OR Apache-2.O
+//	       ^
+func Caret(input string, pos int) string {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(input) {
+		pos = len(input)
+	}
+	return input + "\n" + strings.Repeat(" ", pos) + "^"
+}
@@ -0,0 +1,158 @@
+package expression
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_Canonical(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"MIT", "MIT"},
+		{"MIT OR Apache-2.0", "MIT OR Apache-2.0"},
+		{"mit or Apache-2.0", "mit OR Apache-2.0"},
+		{"GPL-2.0+", "GPL-2.0+"},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0"},
+		{"MIT AND BSD-3-Clause OR Apache-2.0", "MIT AND BSD-3-Clause OR Apache-2.0"},
+		{"MIT AND (BSD-3-Clause OR Apache-2.0)", "MIT AND (BSD-3-Clause OR Apache-2.0)"},
+		{"((MIT))", "MIT"},
+		{"LicenseRef-acme-eula", "LicenseRef-acme-eula"},
+		{"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2"},
+		{"Apache-2.0 WITH LLVM-exception AND (MIT OR ISC)", "Apache-2.0 WITH LLVM-exception AND (MIT OR ISC)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			e, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got := e.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Precedence(t *testing.T) {
+	e, err := Parse("MIT OR Apache-2.0 AND ISC WITH LLVM-exception")
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := e.(*Binary)
+	if !ok || or.Op != Or {
+		t.Fatalf("root = %#v, want OR", e)
+	}
+	and, ok := or.Right.(*Binary)
+	if !ok || and.Op != And {
+		t.Fatalf("right = %#v, want AND", or.Right)
+	}
+	with, ok := and.Right.(*With)
+	if !ok || with.License.ID != "ISC" || with.Exception != "LLVM-exception" {
+		t.Errorf("AND right = %#v, want ISC WITH LLVM-exception", and.Right)
+	}
+}
+
+func TestParse_LeafFields(t *testing.T) {
+	e, err := Parse("DocumentRef-d:LicenseRef-x OR GPL-3.0+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := Terms(e)
+	if len(terms) != 2 {
+		t.Fatalf("terms = %+v", terms)
+	}
+	if l := terms[0].License; l.ID != "LicenseRef-x" || l.DocumentRef != "DocumentRef-d" || !l.IsRef() || l.Pos != 0 {
+		t.Errorf("terms[0] = %+v", l)
+	}
+	if l := terms[1].License; l.ID != "GPL-3.0" || !l.OrLater || l.IsRef() || l.Pos != 30 {
+		t.Errorf("terms[1] = %+v", l)
+	}
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		in  string
+		pos int
+	}{
+		{"", 0},
+		{"   ", 0},
+		{"MIT OR", 6},
+		{"OR MIT", 0},
+		{"MIT Apache-2.0", 4},
+		{"(MIT", 4},
+		{"MIT)", 3},
+		{"MIT WITH", 8},
+		{"(MIT OR ISC) WITH LLVM-exception", 13},
+		{"MIT / ISC", 4},
+		{"MIT++", 3},
+		{"LicenseRef-", 0},
+		{"Foo:LicenseRef-x", 0},
+		{"DocumentRef-d:MIT", 14},
+		{"MIT And ISC", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Parse(%q) err = %v, want *SyntaxError", tt.in, err)
+			}
+			if se.Pos != tt.pos {
+				t.Errorf("Parse(%q) pos = %d (%v), want %d", tt.in, se.Pos, se, tt.pos)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	licenses := map[string]bool{"MIT": true, "Apache-2.0": true, "GPL-2.0-only": true}
+	exceptions := map[string]bool{"Classpath-exception-2.0": true}
+
+	e, _ := Parse("MIT OR Apache-2.0 OR DocumentRef-spdx:LicenseRef-other OR GPL-2.0-only WITH Classpath-exception-2.0")
+	if err := Validate(e, licenses, exceptions); err != nil {
+		t.Errorf("Validate(valid): %v", err)
+	}
+
+	// A LicenseRef- is known only when licenses lists it.
+	e, _ = Parse("MIT OR LicenseRef-internal")
+	if err := Validate(e, licenses, exceptions); err == nil {
+		t.Error("Validate(unknown LicenseRef-): expected error")
+	} else if u := (*UnknownIDError)(nil); !errors.As(err, &u) || !u.Ref || u.Error() != `column 8: unknown license reference ID "LicenseRef-internal"` {
+		t.Errorf("unknown LicenseRef- = %v", err)
+	}
+	if err := Validate(e, map[string]bool{"MIT": true, "LicenseRef-internal": true}, exceptions); err != nil {
+		t.Errorf("Validate(known LicenseRef-): %v", err)
+	}
+
+	// IDs match in any case and take their listed spelling.
+	e, _ = Parse("mit OR apache-2.0 AND gpl-2.0-only WITH classpath-exception-2.0")
+	if err := Validate(e, licenses, exceptions); err != nil {
+		t.Errorf("Validate(lower case): %v", err)
+	}
+	if got := e.String(); got != "MIT OR Apache-2.0 AND GPL-2.0-only WITH Classpath-exception-2.0" {
+		t.Errorf("canonical = %q", got)
+	}
+
+	e, _ = Parse("MIT OR Apache-2.O WITH Nope-exception")
+	err := Validate(e, licenses, exceptions)
+	if err == nil {
+		t.Fatal("Validate: expected error for unknown IDs")
+	}
+	var u *UnknownIDError
+	if !errors.As(err, &u) || u.ID != "Apache-2.O" || u.Pos != 7 || u.Exception {
+		t.Errorf("first unknown = %+v", u)
+	}
+	if got := err.Error(); got != "column 8: unknown license ID \"Apache-2.O\"\ncolumn 24: unknown exception ID \"Nope-exception\"" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestCaret(t *testing.T) {
+	if got := Caret("MIT OR )", 7); got != "MIT OR )\n       ^" {
+		t.Errorf("Caret = %q", got)
+	}
+	if got := Caret("MIT", 99); got != "MIT\n   ^" {
+		t.Errorf("Caret past end = %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokID tokenKind = iota
+	tokAnd
+	tokOr
+	tokWith
+	tokLParen
+	tokRParen
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// Parse parses an SPDX license expression. Operator precedence is WITH > AND > OR; parentheses
+// group. Operators must be all upper case or all lower case (AND/and). Returns a *SyntaxError on
+// malformed input.
+func Parse(s string) (Expr, error) {
+	toks, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty expression"}
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return e, nil
+}
+
+func isIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == ':' || c == '+'
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case isIDChar(c):
+			start := i
+			for i < len(s) && isIDChar(s[i]) {
+				i++
+			}
+			word := s[start:i]
+			kind := tokID
+			switch word {
+			case "AND", "and":
+				kind = tokAnd
+			case "OR", "or":
+				kind = tokOr
+			case "WITH", "with":
+				kind = tokWith
+			}
+			toks = append(toks, token{kind, word, start})
+		default:
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("invalid character %q", c)}
+		}
+	}
+	return append(toks, token{tokEOF, "end of expression", len(s)}), nil
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: Or, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseWith()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseWith()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: And, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseWith() (Expr, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected \")\", got %q", t.text)}
+		}
+		if t := p.peek(); t.kind == tokWith {
+			return nil, &SyntaxError{Pos: t.pos, Msg: "WITH must follow a single license ID, not a parenthesized expression"}
+		}
+		return e, nil
+	}
+	lic, err := p.parseLicense()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokWith {
+		return lic, nil
+	}
+	p.next()
+	t := p.next()
+	if t.kind != tokID {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected exception ID after WITH, got %q", t.text)}
+	}
+	if err := checkIDString(t.text, t.pos); err != nil {
+		return nil, err
+	}
+	return &With{License: lic, Exception: t.text, ExceptionPos: t.pos}, nil
+}
+
+func (p *parser) parseLicense() (*License, error) {
+	t := p.next()
+	if t.kind != tokID {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected license ID, got %q", t.text)}
+	}
+	l := &License{ID: t.text, Pos: t.pos}
+	idPos := t.pos
+	if strings.HasSuffix(l.ID, "+") {
+		l.ID = strings.TrimSuffix(l.ID, "+")
+		l.OrLater = true
+	}
+	if doc, ref, ok := strings.Cut(l.ID, ":"); ok {
+		if !strings.HasPrefix(doc, "DocumentRef-") || len(doc) == len("DocumentRef-") {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("invalid document reference %q", doc)}
+		}
+		if err := checkIDString(doc, t.pos); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(ref, "LicenseRef-") {
+			return nil, &SyntaxError{Pos: t.pos + len(doc) + 1, Msg: "DocumentRef- must be followed by a LicenseRef-"}
+		}
+		l.DocumentRef, l.ID = doc, ref
+		idPos += len(doc) + 1
+	}
+	if l.ID == "LicenseRef-" {
+		return nil, &SyntaxError{Pos: t.pos, Msg: "empty LicenseRef-"}
+	}
+	if err := checkIDString(l.ID, idPos); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// checkIDString rejects characters that tokenize lets through but an SPDX idstring may not contain.
+func checkIDString(id string, pos int) error {
+	if i := strings.IndexAny(id, ":+"); i >= 0 {
+		return &SyntaxError{Pos: pos + i, Msg: fmt.Sprintf("unexpected %q in ID %q", id[i], id)}
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package spdx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Client fetches SPDX JSON over HTTP with a shared http.Client, retrying transient failures (network
+// errors, 5xx, 429) with exponential backoff and jitter. A Retry-After header on 429/503 replaces the
+// backoff delay. Waits are cut short by context cancellation. The zero value uses the defaults below.
+//
+// Given several mirror URLs, Client tries them in order. A per-host circuit breaker skips a host for
+// BreakerCooldown after BreakerThreshold consecutive failed fetches.
+type Client struct {
+	// Timeout bounds each attempt (default 30s; ligma sets it from http_timeout).
+	Timeout time.Duration
+	// Retries is the number of extra attempts after a transient failure; negative means none
+	// (default 2).
+	Retries int
+	// BaseDelay is the backoff before the first retry, doubled for each further retry (default 500ms).
+	BaseDelay time.Duration
+	// MaxDelay caps every wait, Retry-After included (default 30s).
+	MaxDelay time.Duration
+	// BreakerThreshold is the number of consecutive failed fetches that opens a host's breaker (default 3).
+	BreakerThreshold int
+	// BreakerCooldown is how long an open breaker skips its host (default 1m).
+	BreakerCooldown time.Duration
+	// Logf, if set, reports when a mirror other than the first answers or a host's breaker opens.
+	Logf func(format string, args ...any)
+	// Transport makes the requests (see NewTransport); nil means http.DefaultTransport.
+	Transport http.RoundTripper
+	// UserAgent, if set, is sent as the User-Agent header.
+	UserAgent string
+	// Tokens maps a host (as in the URL, with any port) to a bearer token sent to it. Tokens are only
+	// sent over HTTPS.
+	Tokens map[string]string
+
+	once sync.Once
+	http *http.Client
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// breaker is the circuit breaker state of one host.
+type breaker struct {
+	failures  int
+	openUntil time.Time
+}
+
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultRetries   = 2
+	defaultBaseDelay = 500 * time.Millisecond
+	defaultMaxDelay  = 30 * time.Second
+
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = time.Minute
+)
+
+// DefaultClient is used by the Fetch* functions and by an HTTPSource without a Client.
+var DefaultClient = &Client{}
+
+func (c *Client) httpClient() *http.Client {
+	c.once.Do(func() {
+		t := c.Timeout
+		if t <= 0 {
+			t = defaultTimeout
+		}
+		c.http = &http.Client{Timeout: t, Transport: c.Transport}
+	})
+	return c.http
+}
+
+func (c *Client) retries() int {
+	switch {
+	case c.Retries < 0:
+		return 0
+	case c.Retries == 0:
+		return defaultRetries
+	}
+	return c.Retries
+}
+
+// retryError is a failed attempt worth retrying, with the server's Retry-After wait if it sent one.
+type retryError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+func (e *retryError) Unwrap() error { return e.err }
+
+// getJSONFrom is getJSON over an ordered list of mirror URLs: each is tried in turn (hosts with an open
+// breaker are skipped) until one answers. When no mirror answers but one said 404, the result is
+// ErrNotFound (a mirror may lag behind the others); otherwise the last mirror's error is returned.
+func (c *Client) getJSONFrom(ctx context.Context, urls []string, what string, notFound bool, prev Validators, v any) (Validators, error) {
+	var lastErr error
+	sawNotFound := false
+	for i, u := range urls {
+		host := hostOf(u)
+		if !c.allow(host) {
+			lastErr = fmt.Errorf("spdx: %s fetch skipped: %s failed repeatedly", what, host)
+			continue
+		}
+		if i > 0 {
+			reflect.ValueOf(v).Elem().SetZero() // drop anything a failed mirror decoded
+		}
+		next, err := c.getJSON(ctx, u, what, notFound, prev, v)
+		switch {
+		case err == nil || errors.Is(err, ErrNotModified):
+			c.record(host, true)
+			if i > 0 && c.Logf != nil {
+				c.Logf("spdx: %s served by mirror %s", what, u)
+			}
+			return next, err
+		case errors.Is(err, ErrNotFound):
+			c.record(host, true)
+			sawNotFound = true
+		case ctx.Err() != nil:
+			return Validators{}, err
+		default:
+			c.record(host, false)
+			lastErr = err
+		}
+	}
+	if sawNotFound {
+		return Validators{}, ErrNotFound
+	}
+	return Validators{}, lastErr
+}
+
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+// allow reports whether host's breaker is closed (or its cooldown has passed).
+func (c *Client) allow(host string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.breakers[host]
+	return b == nil || !time.Now().Before(b.openUntil)
+}
+
+// record counts a fetch outcome for host and opens its breaker after too many consecutive failures.
+func (c *Client) record(host string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.breakers == nil {
+		c.breakers = make(map[string]*breaker)
+	}
+	b := c.breakers[host]
+	if b == nil {
+		b = &breaker{}
+		c.breakers[host] = b
+	}
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	threshold := c.BreakerThreshold
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if b.failures >= threshold {
+		cooldown := c.BreakerCooldown
+		if cooldown <= 0 {
+			cooldown = defaultBreakerCooldown
+		}
+		b.openUntil = time.Now().Add(cooldown)
+		b.failures = 0
+		if c.Logf != nil {
+			c.Logf("spdx: %s failed %d times in a row; skipping it for %v", host, threshold, cooldown)
+		}
+	}
+}
+
+// getJSON GETs url and decodes the JSON body into v. what names the resource in error messages
+// (e.g. "list", "details"). When notFound is set, HTTP 404 returns ErrNotFound; otherwise any
+// non-2xx is a generic error. On 4xx/5xx the body is discarded, not parsed.
+//
+// Non-zero prev validators are sent as If-None-Match / If-Modified-Since; a 304 answer returns
+// ErrNotModified with prev. On success the response's validators are returned.
+func (c *Client) getJSON(ctx context.Context, url, what string, notFound bool, prev Validators, v any) (Validators, error) {
+	for attempt := 0; ; attempt++ {
+		next, err := c.attempt(ctx, url, what, notFound, prev, v)
+		var re *retryError
+		if !errors.As(err, &re) {
+			return next, err
+		}
+		if attempt >= c.retries() {
+			return Validators{}, re.err
+		}
+		if werr := c.wait(ctx, attempt, re.retryAfter); werr != nil {
+			return Validators{}, re.err
+		}
+	}
+}
+
+// attempt makes one request. Transient failures are returned as *retryError.
+func (c *Client) attempt(ctx context.Context, url, what string, notFound bool, prev Validators, v any) (Validators, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Validators{}, fmt.Errorf("spdx: new request: %w", err)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if tok := c.Tokens[req.URL.Host]; tok != "" && req.URL.Scheme == "https" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		err = fmt.Errorf("spdx: fetch: %w", err)
+		if ctx.Err() != nil {
+			return Validators{}, err
+		}
+		return Validators{}, &retryError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && !prev.IsZero() {
+		return prev, ErrNotModified
+	}
+	if notFound && resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return Validators{}, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		err := fmt.Errorf("spdx: %s fetch failed: HTTP %s", what, resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			re := &retryError{err: err}
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+				re.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			}
+			return Validators{}, re
+		}
+		return Validators{}, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return Validators{}, fmt.Errorf("spdx: invalid JSON: %w", err)
+	}
+	return Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, nil
+}
+
+// wait sleeps before retry number attempt+1: retryAfter when the server sent one, otherwise
+// BaseDelay·2^attempt with jitter (a random point in its upper half), capped at MaxDelay.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	maxDelay := c.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	d := retryAfter
+	if d <= 0 {
+		base := c.BaseDelay
+		if base <= 0 {
+			base = defaultBaseDelay
+		}
+		d = base << attempt
+		if d <= 0 || d > maxDelay {
+			d = maxDelay
+		}
+		d = d/2 + rand.N(d/2+1)
+	}
+	d = min(d, maxDelay)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// parseRetryAfter reads a Retry-After header: delay-seconds or an HTTP date. 0 if absent or invalid.
+func parseRetryAfter(h string, now time.Time) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(h); err == nil {
+		if s < 0 {
+			return 0
+		}
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,247 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fastRetries makes DefaultClient retry without noticeable delays for the rest of the test.
+func fastRetries(t *testing.T) {
+	t.Helper()
+	save := DefaultClient
+	DefaultClient = &Client{BaseDelay: time.Millisecond}
+	t.Cleanup(func() { DefaultClient = save })
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	calls, failures := 0, 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{ListURL: srv.URL, Client: &Client{Retries: 2, BaseDelay: time.Millisecond}}
+	if _, err := src.LicenseList(context.Background()); err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+
+	calls, failures = 0, 5
+	src.Client = &Client{Retries: 1, BaseDelay: time.Millisecond}
+	if _, err := src.LicenseList(context.Background()); err == nil {
+		t.Error("LicenseList: expected error once retries are exhausted")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestClient_NoRetryOnClientErrors(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{DetailsURLTemplate: srv.URL + "/{id}.json", Client: &Client{BaseDelay: time.Millisecond}}
+	if _, err := src.LicenseDetails(context.Background(), "MIT"); err != ErrNotFound {
+		t.Errorf("LicenseDetails err = %v, want ErrNotFound", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+
+	src.Client = &Client{Retries: -1}
+	calls = 0
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	if _, err := src.LicenseDetails(context.Background(), "MIT"); err == nil || calls != 1 {
+		t.Errorf("Retries -1: err = %v, calls = %d; want error after 1 call", err, calls)
+	}
+}
+
+func TestClient_HonorsRetryAfter(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer srv.Close()
+
+	// The backoff alone would wait an hour: the 1s Retry-After must win.
+	src := &HTTPSource{ListURL: srv.URL, Client: &Client{BaseDelay: time.Hour, MaxDelay: time.Hour}}
+	start := time.Now()
+	if _, err := src.LicenseList(context.Background()); err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	if d := time.Since(start); d < 900*time.Millisecond || d > 10*time.Second {
+		t.Errorf("waited %v, want about 1s", d)
+	}
+}
+
+func TestClient_ContextCancelStopsWaiting(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	src := &HTTPSource{ListURL: srv.URL, Client: &Client{BaseDelay: time.Hour, MaxDelay: time.Hour}}
+	start := time.Now()
+	if _, err := src.LicenseList(ctx); err == nil {
+		t.Fatal("LicenseList: expected error")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("cancellation took %v", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Duration{
+		"":                              0,
+		"7":                             7 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Wed, 01 Jan 2025 00:00:30 GMT": 30 * time.Second,
+		"Tue, 31 Dec 2024 23:00:00 GMT": 0,
+	} {
+		if got := parseRetryAfter(in, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestClient_MirrorFallback(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer up.Close()
+
+	var logged []string
+	c := &Client{Retries: -1, Logf: func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }}
+	src := &HTTPSource{ListURL: down.URL, ListMirrors: []string{up.URL}, Client: c}
+	list, err := src.LicenseList(context.Background())
+	if err != nil || len(list.Licenses) != 2 {
+		t.Fatalf("LicenseList = %+v, %v", list, err)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], up.URL) {
+		t.Errorf("log = %q, want the answering mirror", logged)
+	}
+}
+
+func TestClient_MirrorNotFound(t *testing.T) {
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer missing.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	full := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goodDetailsJSON))
+	}))
+	defer full.Close()
+	c := &Client{Retries: -1}
+	ctx := context.Background()
+
+	// A lagging mirror's 404 falls through to the next one.
+	src := &HTTPSource{DetailsURLTemplate: missing.URL + "/{id}", DetailsTemplateMirrors: []string{full.URL + "/{id}"}, Client: c}
+	if d, err := src.LicenseDetails(ctx, "MIT"); err != nil || d.LicenseID != "MIT" {
+		t.Errorf("LicenseDetails = %+v, %v", d, err)
+	}
+	// A 404 from one mirror and a failure from the other: the ID is reported unknown.
+	src = &HTTPSource{DetailsURLTemplate: missing.URL + "/{id}", DetailsTemplateMirrors: []string{down.URL + "/{id}"}, Client: c}
+	if _, err := src.LicenseDetails(ctx, "Nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LicenseDetails err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	calls := 0
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer up.Close()
+
+	c := &Client{Retries: -1, BreakerThreshold: 2, BreakerCooldown: time.Hour}
+	src := &HTTPSource{ListURL: down.URL, ListMirrors: []string{up.URL}, Client: c}
+	for i := 0; i < 4; i++ {
+		if _, err := src.LicenseList(context.Background()); err != nil {
+			t.Fatalf("LicenseList #%d: %v", i, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls to the failing host = %d, want 2 (breaker open after that)", calls)
+	}
+
+	// With every host's breaker open, the fetch fails without a request.
+	src = &HTTPSource{ListURL: down.URL, Client: c}
+	if _, err := src.LicenseList(context.Background()); err == nil || calls != 2 {
+		t.Errorf("LicenseList with open breaker: err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestClient_UserAgentAndToken(t *testing.T) {
+	var ua, auth string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua, auth = r.Header.Get("User-Agent"), r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	c := &Client{Retries: -1, Transport: srv.Client().Transport, UserAgent: "ligma/1.2.3", Tokens: map[string]string{host: "s3cret"}}
+	if _, _, err := fetchLicenseList(context.Background(), c, []string{srv.URL}, Validators{}); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if ua != "ligma/1.2.3" || auth != "Bearer s3cret" {
+		t.Errorf("User-Agent = %q, Authorization = %q", ua, auth)
+	}
+
+	// Tokens are never sent in the clear.
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer plain.Close()
+	c = &Client{Retries: -1, Tokens: map[string]string{strings.TrimPrefix(plain.URL, "http://"): "s3cret"}}
+	if _, _, err := fetchLicenseList(context.Background(), c, []string{plain.URL}, Validators{}); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if auth != "" {
+		t.Errorf("Authorization over plain HTTP = %q, want none", auth)
+	}
+}
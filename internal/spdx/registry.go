@@ -0,0 +1,131 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// RegistrySource merges an organization's license registry into Upstream. Registry serves a
+// licenses.json-shaped index of the organization's licenses, usually LicenseRef- IDs, with their
+// details; its Aliases map the organization's names for licenses to IDs. Upstream wins every conflict:
+// a registry license with an ID Upstream lists is left out, as is the second registry license with the
+// same ID and any alias that is an ID Upstream lists, in any case (only the user's own aliases may
+// shadow an ID). The user's own Aliases win over the registry's too. Each conflict is reported through
+// Warnf, and so is a registry that cannot be read, in which case Upstream is served alone. Exceptions
+// are Upstream's alone.
+//
+// The registry's aliases are only read when an ID is not found: LicenseDetails of an ID that neither
+// source has looks it up among them.
+type RegistrySource struct {
+	Upstream Source
+	Registry Source
+	// Aliases are the user's own aliases; a registry alias with the same name is left out.
+	Aliases map[string]string
+	// Warnf, if set, reports the registry licenses and aliases left out and a failing registry.
+	Warnf func(format string, args ...any)
+}
+
+// LicenseList implements Source: Upstream's list with the registry's licenses appended, marked IsOrg,
+// and the registry's aliases.
+func (s *RegistrySource) LicenseList(ctx context.Context) (*LicenseList, error) {
+	list, err := s.Upstream.LicenseList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := s.Registry.LicenseList(ctx)
+	if err != nil {
+		s.warnf("organization license registry: %v; using the SPDX licenses only", err)
+		return list, nil
+	}
+	listed := make(map[string]bool, len(list.Licenses))
+	upstreamIDs := make(map[string]string, len(list.Licenses))
+	for _, l := range list.Licenses {
+		listed[l.LicenseID] = true
+		upstreamIDs[strings.ToLower(l.LicenseID)] = l.LicenseID
+	}
+	merged := *list
+	merged.Licenses = append([]License(nil), list.Licenses...)
+	for _, l := range reg.Licenses {
+		if listed[l.LicenseID] {
+			s.warnf("%s in the organization license registry is already listed; ignoring the registry's entry", l.LicenseID)
+			continue
+		}
+		listed[l.LicenseID] = true
+		l.IsOrg = true
+		merged.Licenses = append(merged.Licenses, l)
+	}
+	if len(reg.Aliases) > 0 {
+		merged.Aliases = make(map[string]string, len(list.Aliases)+len(reg.Aliases))
+		for k, v := range reg.Aliases {
+			if id, ok := upstreamIDs[strings.ToLower(k)]; ok {
+				s.warnf("alias %q in the organization license registry would replace the license %s; ignoring it", k, id)
+				continue
+			}
+			if own, ok := s.Aliases[k]; ok {
+				if own != v {
+					s.warnf("alias %q is %s in your aliases and %s in the organization license registry; using %s", k, own, v, own)
+				}
+				continue
+			}
+			merged.Aliases[k] = v
+		}
+		for k, v := range list.Aliases {
+			merged.Aliases[k] = v
+		}
+	}
+	return &merged, nil
+}
+
+// LicenseDetails implements Source: a LicenseRef- ID from the registry (SPDX never lists those), any
+// other ID from Upstream, and from the registry only when Upstream does not have it. An ID neither has
+// that is an alias of the merged list is looked up as the ID it stands for.
+func (s *RegistrySource) LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error) {
+	d, err := s.details(ctx, id)
+	if !errors.Is(err, ErrNotFound) {
+		return d, err
+	}
+	list, lerr := s.LicenseList(ctx)
+	if lerr != nil {
+		return nil, err
+	}
+	if target, ok := list.Aliases[id]; ok && target != id {
+		return s.details(ctx, target)
+	}
+	return nil, err
+}
+
+// details is LicenseDetails without aliases.
+func (s *RegistrySource) details(ctx context.Context, id string) (*LicenseDetails, error) {
+	if strings.HasPrefix(id, CustomPrefix) {
+		d, err := s.Registry.LicenseDetails(ctx, id)
+		if !errors.Is(err, ErrNotFound) {
+			return d, err
+		}
+		return s.Upstream.LicenseDetails(ctx, id)
+	}
+	d, err := s.Upstream.LicenseDetails(ctx, id)
+	if !errors.Is(err, ErrNotFound) {
+		return d, err
+	}
+	if rd, rerr := s.Registry.LicenseDetails(ctx, id); rerr == nil {
+		return rd, nil
+	}
+	return nil, err
+}
+
+// ExceptionList implements Source.
+func (s *RegistrySource) ExceptionList(ctx context.Context) (*ExceptionList, error) {
+	return s.Upstream.ExceptionList(ctx)
+}
+
+// ExceptionDetails implements Source.
+func (s *RegistrySource) ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error) {
+	return s.Upstream.ExceptionDetails(ctx, id)
+}
+
+func (s *RegistrySource) warnf(format string, args ...any) {
+	if s.Warnf != nil {
+		s.Warnf(format, args...)
+	}
+}
@@ -0,0 +1,85 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRegistrySource(t *testing.T) {
+	var warnings []string
+	s := &RegistrySource{
+		Upstream: &MemorySource{
+			Licenses:           &LicenseList{LicenseListVersion: "3.27", Licenses: []License{{LicenseID: "MIT"}}},
+			LicenseDetailsByID: map[string]*LicenseDetails{"MIT": {LicenseID: "MIT", LicenseText: "SPDX MIT"}},
+		},
+		Registry: &MemorySource{
+			Licenses: &LicenseList{
+				Licenses: []License{
+					{LicenseID: "LicenseRef-Acme", Name: "Acme License"},
+					{LicenseID: "MIT", Name: "Acme's MIT"},
+					{LicenseID: "Acme-Internal"},
+					{LicenseID: "LicenseRef-Acme", Name: "Acme License again"},
+				},
+				Aliases: map[string]string{"acme": "LicenseRef-Acme", "mit": "LicenseRef-Acme", "corp": "MIT", "own": "MIT"},
+			},
+			LicenseDetailsByID: map[string]*LicenseDetails{
+				"LicenseRef-Acme": {LicenseID: "LicenseRef-Acme", LicenseText: "Acme text"},
+				"MIT":             {LicenseID: "MIT", LicenseText: "Acme MIT"},
+				"Acme-Internal":   {LicenseID: "Acme-Internal", LicenseText: "internal text"},
+			},
+		},
+		Aliases: map[string]string{"own": "Acme-Internal"},
+		Warnf:   func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) },
+	}
+	ctx := context.Background()
+
+	list, err := s.LicenseList(ctx)
+	if err != nil {
+		t.Fatalf("LicenseList: %v", err)
+	}
+	var ids []string
+	for _, l := range list.Licenses {
+		ids = append(ids, fmt.Sprintf("%s/%s/%v", l.LicenseID, l.Name, l.IsOrg))
+	}
+	if got, want := fmt.Sprint(ids), "[MIT//false LicenseRef-Acme/Acme License/true Acme-Internal//true]"; got != want {
+		t.Errorf("licenses = %s, want %s", got, want)
+	}
+	if list.LicenseListVersion != "3.27" || list.Aliases["acme"] != "LicenseRef-Acme" || list.Aliases["mit"] != "" || list.Aliases["own"] != "" {
+		t.Errorf("list = %+v", list)
+	}
+	if len(warnings) != 4 {
+		t.Errorf("warnings = %q, want the registry's MIT, second LicenseRef-Acme, mit alias and own alias", warnings)
+	}
+
+	for id, want := range map[string]string{"MIT": "SPDX MIT", "LicenseRef-Acme": "Acme text", "Acme-Internal": "internal text", "acme": "Acme text", "corp": "SPDX MIT"} {
+		if d, err := s.LicenseDetails(ctx, id); err != nil || d.LicenseText != want {
+			t.Errorf("LicenseDetails(%s) = %+v, %v; want %q", id, d, err, want)
+		}
+	}
+	for _, id := range []string{"LicenseRef-Nope", "Nope", "mit", "own"} {
+		if _, err := s.LicenseDetails(ctx, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("LicenseDetails(%s): expected ErrNotFound, got %v", id, err)
+		}
+	}
+}
+
+func TestRegistrySource_RegistryFails(t *testing.T) {
+	var warnings []string
+	s := &RegistrySource{
+		Upstream: &MemorySource{Licenses: &LicenseList{Licenses: []License{{LicenseID: "MIT"}}}},
+		Registry: &MemorySource{Err: errors.New("registry down")},
+		Warnf:    func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) },
+	}
+	list, err := s.LicenseList(context.Background())
+	if err != nil || len(list.Licenses) != 1 {
+		t.Fatalf("LicenseList = %+v, %v; want the SPDX list alone", list, err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q", warnings)
+	}
+	if _, err := s.LicenseDetails(context.Background(), "LicenseRef-Acme"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("LicenseDetails(LicenseRef-Acme): expected the registry's error, got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package spdx
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Match is an SPDX license ID that informal text may stand for, with how sure Resolve is of it.
+type Match struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"` // in (0, 1]; 1 for the ID itself
+}
+
+// Confidence of each way Resolve can match, best first.
+const (
+	confidenceID       = 1.0  // the ID in any case
+	confidenceURL      = 0.95 // a seeAlso URL or an spdx.org license page
+	confidenceKey      = 0.9  // the ID or name, in other words or spelling (Apache License 2.0, GPL v2 only)
+	confidenceInformal = 0.85 // a common nickname (new BSD, expat), or a deprecated ID for its replacement (GPLv3)
+	confidenceTokens   = 0.8  // the words of the ID or name in another order (3-clause BSD)
+	confidenceURLPath  = 0.8  // the last segment of another URL, resolved as text
+	confidenceFuzzy    = 0.6  // scale of the Suggest similarity, for anything else close
+)
+
+// informalNames maps common nicknames onto SPDX IDs. Spellings that only differ from an ID or name in
+// case, punctuation, "v", "version" or "license" need no entry: informalKey makes them equal.
+var informalNames = map[string]string{
+	"expat":             "MIT",
+	"asl 2":             "Apache-2.0",
+	"new bsd":           "BSD-3-Clause",
+	"modified bsd":      "BSD-3-Clause",
+	"revised bsd":       "BSD-3-Clause",
+	"simplified bsd":    "BSD-2-Clause",
+	"freebsd":           "BSD-2-Clause",
+	"zero clause bsd":   "0BSD",
+	"mpl":               "MPL-2.0",
+	"cc0":               "CC0-1.0",
+	"boost":             "BSL-1.0",
+	"public domain cc0": "CC0-1.0",
+}
+
+// phrases rewrites the long forms of license families to the short forms used in IDs, on the words
+// informalKey has split; longer phrases come first.
+var phrases = [][2]string{
+	{"gnu affero general public", "agpl"},
+	{"gnu lesser general public", "lgpl"},
+	{"gnu library general public", "lgpl"},
+	{"gnu general public", "gpl"},
+	{"gnu free documentation", "gfdl"},
+	{"affero general public", "agpl"},
+	{"lesser general public", "lgpl"},
+	{"library general public", "lgpl"},
+	{"general public", "gpl"},
+	{"gnu gpl", "gpl"},
+	{"gnu lgpl", "lgpl"},
+	{"gnu agpl", "agpl"},
+	{"mozilla public", "mpl"},
+	{"eclipse public", "epl"},
+	{"boost software", "bsl"},
+	{"academic free", "afl"},
+	{"creative commons", "cc"},
+	{"attribution", "by"},
+	{"share alike", "sa"},
+	{"sharealike", "sa"},
+	{"noncommercial", "nc"},
+	{"no derivatives", "nd"},
+	{"noderivatives", "nd"},
+	{"or any later", "or later"},
+	{"or newer", "or later"},
+	{"clauses", "clause"},
+}
+
+// stopWords carry no meaning for matching.
+var stopWords = map[string]bool{"the": true, "license": true, "licence": true, "licenses": true, "version": true, "v": true, "international": true, "universal": true}
+
+var (
+	// familyVersion is a family name run into its version, as in gplv3 or lgpl2.1; versionMark a
+	// version written as v3.
+	familyVersion = regexp.MustCompile(`([a-z]{2,}?)v?([0-9])`)
+	versionMark   = regexp.MustCompile(`\bv([0-9])`)
+	versionLetter = regexp.MustCompile(`([0-9])([a-z])`)
+)
+
+// informalKey normalizes a license ID, name or informal spelling, so that spellings of the same
+// license compare equal: "GNU General Public License v3.0 or later", "GPLv3+" and "GPL-3.0-or-later"
+// are all "gpl 3 or later".
+func informalKey(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, "+", " or later "))
+	s = versionMark.ReplaceAllString(s, "$1")
+	s = familyVersion.ReplaceAllString(s, "$1 $2")
+	s = versionLetter.ReplaceAllString(s, "$1 $2")
+	var words []string
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '.'
+	}) {
+		w = strings.Trim(w, ".")
+		// A ".0" minor version is left out, so that 2 and 2.0 match.
+		for strings.HasSuffix(w, ".0") {
+			w = strings.TrimSuffix(w, ".0")
+		}
+		if w != "" && !stopWords[w] {
+			words = append(words, w)
+		}
+	}
+	key := " " + strings.Join(words, " ") + " "
+	for _, p := range phrases {
+		for strings.Contains(key, " "+p[0]+" ") {
+			key = strings.Replace(key, " "+p[0]+" ", " "+p[1]+" ", 1)
+		}
+	}
+	return strings.TrimSpace(key)
+}
+
+// tokensKey is key with its words sorted, for matching them in any order.
+func tokensKey(key string) string {
+	words := strings.Fields(key)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// urlKey normalizes a URL for comparison: no scheme, www., query, trailing slash or page extension.
+func urlKey(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(strings.ToLower(u.Path), "/")
+	for _, ext := range []string{".html", ".htm", ".php", ".txt", ".json"} {
+		path = strings.TrimSuffix(path, ext)
+	}
+	return host + path
+}
+
+// Resolve maps informal text onto the SPDX IDs of licenses it may stand for, best first: the ID in any
+// case, a seeAlso or spdx.org URL, the ID or name in other words or spelling ("Apache License 2.0",
+// "GPLv3", "BSD 3 clause"), a common nickname, or failing those the IDs Suggest finds close. A
+// deprecated ID stands for its replacement (GPLv3 is GPL-3.0-only), which for the old license-with-
+// exception IDs is an expression ("GPL-2.0 with classpath exception" is GPL-2.0-only WITH
+// Classpath-exception-2.0). Only IDs in licenses are returned, and expressions of their licenses.
+func Resolve(text string, licenses []License) []Match {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	names := make(map[string]string, len(licenses))
+	best := make(map[string]float64)
+	for _, l := range licenses {
+		names[l.LicenseID] = l.Name
+		if strings.EqualFold(l.LicenseID, text) {
+			best[l.LicenseID] = confidenceID
+		}
+	}
+	add := func(id string, confidence float64) {
+		license, exception, with := strings.Cut(id, " WITH ")
+		if _, listed := names[license]; listed {
+			best[id] = max(best[id], confidence)
+			if with {
+				names[id] = names[license] + " with " + exception
+			}
+		}
+	}
+
+	if u, err := url.Parse(text); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		resolveURL(u, licenses, add)
+	} else {
+		resolveText(text, licenses, add)
+	}
+
+	matches := make([]Match, 0, len(best))
+	for id, c := range best {
+		matches = append(matches, Match{ID: id, Name: names[id], Confidence: c})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// resolveText adds the licenses whose ID or name informal text spells differently, the nicknames and
+// deprecated IDs it may be, and the licenses Suggest finds close to it.
+func resolveText(text string, licenses []License, add func(id string, confidence float64)) {
+	// byKey and byTokens hold the IDs by informalKey and tokensKey of their spellings, with the
+	// confidence of each spelling.
+	byKey, byTokens := make(map[string]map[string]float64), make(map[string]map[string]float64)
+	index := func(text, id string, confidence float64) {
+		k := informalKey(text)
+		put(byKey, k, id, confidence)
+		put(byTokens, tokensKey(k), id, confidence)
+	}
+	var candidates []Candidate
+	for _, l := range licenses {
+		confidence := confidenceKey
+		id := l.LicenseID
+		if replacement, deprecated := Deprecation(id, l.IsDeprecatedLicenseID); deprecated && replacement != "" {
+			id, confidence = replacement, confidenceInformal
+		}
+		index(l.LicenseID, id, confidence)
+		index(l.Name, id, confidence)
+		candidates = append(candidates, Candidate{ID: id, Name: l.Name})
+	}
+	// The deprecated spellings stand for their replacements even when the list leaves them out.
+	for old, replacement := range replacements {
+		index(old, replacement, confidenceInformal)
+	}
+	for nickname, id := range informalNames {
+		index(nickname, id, confidenceInformal)
+	}
+
+	key := informalKey(text)
+	for id, confidence := range byKey[key] {
+		add(id, confidence)
+	}
+	for id, confidence := range byTokens[tokensKey(key)] {
+		add(id, min(confidence, confidenceTokens))
+	}
+	for id, s := range scores(text, candidates) {
+		if s >= minSuggestScore {
+			add(id, s*confidenceFuzzy)
+		}
+	}
+}
+
+// resolveURL adds the licenses whose seeAlso has u, the license of an spdx.org/licenses page and, with
+// less confidence, what the last path segment of u resolves to (opensource.org/licenses/MIT).
+func resolveURL(u *url.URL, licenses []License, add func(id string, confidence float64)) {
+	k := urlKey(u)
+	for _, l := range licenses {
+		for _, see := range l.SeeAlso {
+			if su, err := url.Parse(see); err == nil && urlKey(su) == k {
+				add(l.LicenseID, confidenceURL)
+			}
+		}
+	}
+	segment := k[strings.LastIndex(k, "/")+1:]
+	if segment == "" {
+		return
+	}
+	if strings.HasPrefix(k, "spdx.org/licenses/") {
+		for _, l := range licenses {
+			if strings.EqualFold(l.LicenseID, segment) {
+				add(l.LicenseID, confidenceURL)
+			}
+		}
+	}
+	for _, m := range Resolve(segment, licenses) {
+		add(m.ID, m.Confidence*confidenceURLPath)
+	}
+}
+
+// put records id under key in m with the best confidence seen.
+func put(m map[string]map[string]float64, key, id string, confidence float64) {
+	if m[key] == nil {
+		m[key] = make(map[string]float64)
+	}
+	m[key][id] = max(m[key][id], confidence)
+}
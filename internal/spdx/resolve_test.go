@@ -0,0 +1,68 @@
+package spdx
+
+import "testing"
+
+var resolveList = []License{
+	{LicenseID: "MIT", Name: "MIT License", SeeAlso: []string{"https://opensource.org/license/mit/"}},
+	{LicenseID: "Apache-2.0", Name: "Apache License 2.0", SeeAlso: []string{"https://www.apache.org/licenses/LICENSE-2.0"}},
+	{LicenseID: "BSD-3-Clause", Name: `BSD 3-Clause "New" or "Revised" License`},
+	{LicenseID: "GPL-2.0", Name: "GNU General Public License v2.0 only", IsDeprecatedLicenseID: true},
+	{LicenseID: "GPL-2.0-only", Name: "GNU General Public License v2.0 only"},
+	{LicenseID: "GPL-3.0-only", Name: "GNU General Public License v3.0 only"},
+	{LicenseID: "GPL-3.0-or-later", Name: "GNU General Public License v3.0 or later"},
+	{LicenseID: "LGPL-2.1-only", Name: "GNU Lesser General Public License v2.1 only"},
+}
+
+func TestResolve(t *testing.T) {
+	for _, tc := range []struct {
+		text          string
+		id            string
+		minConfidence float64
+	}{
+		{"mit", "MIT", 1},
+		{"Apache License 2.0", "Apache-2.0", 0.9},
+		{"Apache License, Version 2.0", "Apache-2.0", 0.9},
+		{"apache 2", "Apache-2.0", 0.9},
+		{"BSD 3 clause", "BSD-3-Clause", 0.9},
+		{"3-clause BSD", "BSD-3-Clause", 0.8},
+		{"new BSD", "BSD-3-Clause", 0.85},
+		{"expat", "MIT", 0.85},
+		{"GPLv3", "GPL-3.0-only", 0.85},
+		{"GPLv3+", "GPL-3.0-or-later", 0.9},
+		{"GNU GPL version 3 or any later version", "GPL-3.0-or-later", 0.9},
+		{"GPL v2", "GPL-2.0-only", 0.85},
+		{"GPLv3 or later", "GPL-3.0-or-later", 0.9},
+		{"GPL-2.0 with classpath exception", "GPL-2.0-only WITH Classpath-exception-2.0", 0.85},
+		{"lgpl2.1", "LGPL-2.1-only", 0.85},
+		{"https://www.apache.org/licenses/LICENSE-2.0.html", "Apache-2.0", 0.95},
+		{"http://opensource.org/license/mit", "MIT", 0.95},
+		{"https://spdx.org/licenses/BSD-3-Clause.html", "BSD-3-Clause", 0.95},
+		{"https://example.com/licenses/Apache-2.0", "Apache-2.0", 0.8},
+	} {
+		got := Resolve(tc.text, resolveList)
+		if len(got) == 0 || got[0].ID != tc.id || got[0].Confidence < tc.minConfidence {
+			t.Errorf("Resolve(%q) = %+v, want %s first with confidence >= %v", tc.text, got, tc.id, tc.minConfidence)
+		}
+	}
+	for _, text := range []string{"", "zlib", "https://example.com/"} {
+		if got := Resolve(text, resolveList); len(got) != 0 {
+			t.Errorf("Resolve(%q) = %+v, want no match", text, got)
+		}
+	}
+}
+
+func TestInformalKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"GNU General Public License v3.0 or later": "gpl 3 or later",
+		"GPLv3+":                      "gpl 3 or later",
+		"GPL-3.0-or-later":            "gpl 3 or later",
+		"Apache License, Version 2.0": "apache 2",
+		"lgpl2.1":                     "lgpl 2.1",
+		"CC-BY-SA-4.0":                "cc by sa 4",
+		"Creative Commons Attribution Share Alike 4.0 International": "cc by sa 4",
+	} {
+		if got := informalKey(in); got != want {
+			t.Errorf("informalKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package spdx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Source is a backend for SPDX license data: the license and exception lists and per-ID details.
+// Details methods return ErrNotFound for an unknown ID. Implementations: HTTPSource (SPDX URLs),
+// FSSource (a license-list-data json/ directory), MemorySource (in-memory, for tests),
+// FallbackSource (one Source backed by another), RegistrySource (an organization registry merged into
+// another) and CustomSource (user-defined LicenseRef- licenses on top of another); the cache package wraps any Source with the ~/.ligma/_cache file cache.
+type Source interface {
+	LicenseList(ctx context.Context) (*LicenseList, error)
+	LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error)
+	ExceptionList(ctx context.Context) (*ExceptionList, error)
+	ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error)
+}
+
+// HTTPSource fetches SPDX JSON over HTTP with Client (DefaultClient when nil). Empty URL fields use
+// the Default* URLs. The *Mirrors fields are tried in order after the matching URL fails.
+type HTTPSource struct {
+	ListURL                     string
+	DetailsURLTemplate          string
+	ExceptionsURL               string
+	ExceptionDetailsURLTemplate string
+	Client                      *Client
+
+	ListMirrors                     []string
+	DetailsTemplateMirrors          []string
+	ExceptionsMirrors               []string
+	ExceptionDetailsTemplateMirrors []string
+}
+
+func (s *HTTPSource) client() *Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return DefaultClient
+}
+
+// Pinned returns a copy of s with every URL (defaults included) pinned to a license list version
+// with VersionedURL; an empty version keeps the URLs on main.
+func (s *HTTPSource) Pinned(version string) (*HTTPSource, error) {
+	p := &HTTPSource{Client: s.Client}
+	for _, u := range []struct {
+		dst        *string
+		src, def   string
+		dstMirrors *[]string
+		srcMirrors []string
+	}{
+		{&p.ListURL, s.ListURL, DefaultListURL, &p.ListMirrors, s.ListMirrors},
+		{&p.DetailsURLTemplate, s.DetailsURLTemplate, DefaultDetailsURLTemplate, &p.DetailsTemplateMirrors, s.DetailsTemplateMirrors},
+		{&p.ExceptionsURL, s.ExceptionsURL, DefaultExceptionsURL, &p.ExceptionsMirrors, s.ExceptionsMirrors},
+		{&p.ExceptionDetailsURLTemplate, s.ExceptionDetailsURLTemplate, DefaultExceptionDetailsURLTemplate, &p.ExceptionDetailsTemplateMirrors, s.ExceptionDetailsTemplateMirrors},
+	} {
+		v, err := VersionedURL(orDefault(u.src, u.def), version)
+		if err != nil {
+			return nil, err
+		}
+		*u.dst = v
+		for _, m := range u.srcMirrors {
+			v, err := VersionedURL(m, version)
+			if err != nil {
+				return nil, err
+			}
+			*u.dstMirrors = append(*u.dstMirrors, v)
+		}
+	}
+	return p, nil
+}
+
+// urls returns primary (or def when empty) followed by mirrors.
+func urls(primary, def string, mirrors []string) []string {
+	return append([]string{orDefault(primary, def)}, mirrors...)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// LicenseList implements Source like FetchLicenseList.
+func (s *HTTPSource) LicenseList(ctx context.Context) (*LicenseList, error) {
+	list, _, err := s.LicenseListIf(ctx, Validators{})
+	return list, err
+}
+
+// LicenseDetails implements Source like FetchLicenseDetails.
+func (s *HTTPSource) LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error) {
+	d, _, err := s.LicenseDetailsIf(ctx, id, Validators{})
+	return d, err
+}
+
+// ExceptionList implements Source like FetchExceptionList.
+func (s *HTTPSource) ExceptionList(ctx context.Context) (*ExceptionList, error) {
+	list, _, err := s.ExceptionListIf(ctx, Validators{})
+	return list, err
+}
+
+// ExceptionDetails implements Source like FetchExceptionDetails.
+func (s *HTTPSource) ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error) {
+	d, _, err := s.ExceptionDetailsIf(ctx, id, Validators{})
+	return d, err
+}
+
+// FSSource reads the layout of the json/ directory of spdx/license-list-data: licenses.json,
+// details/<id>.json, exceptions.json and exceptions/<id>.json. ListName, if set, replaces licenses.json
+// (an organization registry may name its index differently).
+type FSSource struct {
+	FS       fs.FS
+	ListName string
+}
+
+// NewDirSource returns an FSSource over a local directory, e.g. a checkout's license-list-data/json.
+func NewDirSource(dir string) *FSSource {
+	return &FSSource{FS: os.DirFS(dir)}
+}
+
+// readJSON decodes name from s.FS into v. A missing file is ErrNotFound when notFound is set.
+func (s *FSSource) readJSON(name string, notFound bool, v any) error {
+	b, err := fs.ReadFile(s.FS, name)
+	if err != nil {
+		if notFound && errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("spdx: read %s: %w", name, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("spdx: invalid JSON in %s: %w", name, err)
+	}
+	return nil
+}
+
+// validID rejects IDs that are not a single file name, so details lookups stay inside the directory.
+func validID(id string) bool {
+	return id != "" && !strings.Contains(id, "..") && !strings.ContainsAny(id, `/\`)
+}
+
+// LicenseList implements Source by reading licenses.json (or ListName).
+func (s *FSSource) LicenseList(ctx context.Context) (*LicenseList, error) {
+	var list LicenseList
+	if err := s.readJSON(orDefault(s.ListName, "licenses.json"), false, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// LicenseDetails implements Source by reading details/<id>.json.
+func (s *FSSource) LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	var d LicenseDetails
+	if err := s.readJSON("details/"+id+".json", true, &d); err != nil {
+		return nil, err
+	}
+	if d.LicenseText == "" {
+		return nil, fmt.Errorf("spdx: missing licenseText")
+	}
+	return &d, nil
+}
+
+// ExceptionList implements Source by reading exceptions.json.
+func (s *FSSource) ExceptionList(ctx context.Context) (*ExceptionList, error) {
+	var list ExceptionList
+	if err := s.readJSON("exceptions.json", false, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ExceptionDetails implements Source by reading exceptions/<id>.json.
+func (s *FSSource) ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	var d ExceptionDetails
+	if err := s.readJSON("exceptions/"+id+".json", true, &d); err != nil {
+		return nil, err
+	}
+	if d.LicenseExceptionText == "" {
+		return nil, fmt.Errorf("spdx: missing licenseExceptionText")
+	}
+	return &d, nil
+}
+
+// MemorySource serves license data from memory. A nil list is an error; a missing details entry is
+// ErrNotFound. When Err is set every method returns it (to simulate a failing backend in tests).
+type MemorySource struct {
+	Licenses             *LicenseList
+	LicenseDetailsByID   map[string]*LicenseDetails
+	Exceptions           *ExceptionList
+	ExceptionDetailsByID map[string]*ExceptionDetails
+	Err                  error
+}
+
+// LicenseList implements Source.
+func (s *MemorySource) LicenseList(ctx context.Context) (*LicenseList, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if s.Licenses == nil {
+		return nil, fmt.Errorf("spdx: no license list in memory source")
+	}
+	return s.Licenses, nil
+}
+
+// LicenseDetails implements Source.
+func (s *MemorySource) LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if d, ok := s.LicenseDetailsByID[id]; ok {
+		return d, nil
+	}
+	return nil, ErrNotFound
+}
+
+// ExceptionList implements Source.
+func (s *MemorySource) ExceptionList(ctx context.Context) (*ExceptionList, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if s.Exceptions == nil {
+		return nil, fmt.Errorf("spdx: no exception list in memory source")
+	}
+	return s.Exceptions, nil
+}
+
+// ExceptionDetails implements Source.
+func (s *MemorySource) ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if d, ok := s.ExceptionDetailsByID[id]; ok {
+		return d, nil
+	}
+	return nil, ErrNotFound
+}
+
+// FallbackSource serves from Primary and, when Primary fails with anything other than ErrNotFound,
+// from Fallback (e.g. the embedded snapshot when both cache and network are unavailable). If
+// Fallback fails too, Primary's error is returned. OnFallback, if set, is called with Primary's
+// error whenever Fallback answers.
+type FallbackSource struct {
+	Primary    Source
+	Fallback   Source
+	OnFallback func(err error)
+}
+
+func withFallback[T any](s *FallbackSource, get func(Source) (T, error)) (T, error) {
+	v, err := get(s.Primary)
+	if err == nil || errors.Is(err, ErrNotFound) || s.Fallback == nil {
+		return v, err
+	}
+	fv, ferr := get(s.Fallback)
+	if ferr != nil {
+		return v, err
+	}
+	if s.OnFallback != nil {
+		s.OnFallback(err)
+	}
+	return fv, nil
+}
+
+// LicenseList implements Source.
+func (s *FallbackSource) LicenseList(ctx context.Context) (*LicenseList, error) {
+	return withFallback(s, func(src Source) (*LicenseList, error) { return src.LicenseList(ctx) })
+}
+
+// LicenseDetails implements Source.
+func (s *FallbackSource) LicenseDetails(ctx context.Context, id string) (*LicenseDetails, error) {
+	return withFallback(s, func(src Source) (*LicenseDetails, error) { return src.LicenseDetails(ctx, id) })
+}
+
+// ExceptionList implements Source.
+func (s *FallbackSource) ExceptionList(ctx context.Context) (*ExceptionList, error) {
+	return withFallback(s, func(src Source) (*ExceptionList, error) { return src.ExceptionList(ctx) })
+}
+
+// ExceptionDetails implements Source.
+func (s *FallbackSource) ExceptionDetails(ctx context.Context, id string) (*ExceptionDetails, error) {
+	return withFallback(s, func(src Source) (*ExceptionDetails, error) { return src.ExceptionDetails(ctx, id) })
+}
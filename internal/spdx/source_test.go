@@ -0,0 +1,178 @@
+package spdx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/licenses.json":
+			_, _ = w.Write([]byte(goodJSON))
+		case "/details/MIT.json":
+			_, _ = w.Write([]byte(goodDetailsJSON))
+		case "/exceptions.json":
+			_, _ = w.Write([]byte(goodExceptionsJSON))
+		case "/exceptions/LLVM-exception.json":
+			_, _ = w.Write([]byte(`{"licenseExceptionId":"LLVM-exception","licenseExceptionText":"text"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{
+		ListURL:                     srv.URL + "/licenses.json",
+		DetailsURLTemplate:          srv.URL + "/details/{id}.json",
+		ExceptionsURL:               srv.URL + "/exceptions.json",
+		ExceptionDetailsURLTemplate: srv.URL + "/exceptions/{id}.json",
+	}
+	ctx := context.Background()
+	if list, err := src.LicenseList(ctx); err != nil || len(list.Licenses) != 2 {
+		t.Errorf("LicenseList = %+v, %v", list, err)
+	}
+	if d, err := src.LicenseDetails(ctx, "MIT"); err != nil || d.LicenseID != "MIT" {
+		t.Errorf("LicenseDetails = %+v, %v", d, err)
+	}
+	if _, err := src.LicenseDetails(ctx, "Nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LicenseDetails(Nope) err = %v, want ErrNotFound", err)
+	}
+	if list, err := src.ExceptionList(ctx); err != nil || len(list.Exceptions) != 1 {
+		t.Errorf("ExceptionList = %+v, %v", list, err)
+	}
+	if d, err := src.ExceptionDetails(ctx, "LLVM-exception"); err != nil || d.LicenseExceptionText != "text" {
+		t.Errorf("ExceptionDetails = %+v, %v", d, err)
+	}
+}
+
+func TestFSSource(t *testing.T) {
+	src := &FSSource{FS: fstest.MapFS{
+		"licenses.json":                  {Data: []byte(goodJSON)},
+		"details/MIT.json":               {Data: []byte(goodDetailsJSON)},
+		"details/Broken.json":            {Data: []byte(`{invalid`)},
+		"exceptions.json":                {Data: []byte(goodExceptionsJSON)},
+		"exceptions/LLVM-exception.json": {Data: []byte(`{"licenseExceptionId":"LLVM-exception","licenseExceptionText":"text"}`)},
+	}}
+	ctx := context.Background()
+	if list, err := src.LicenseList(ctx); err != nil || list.LicenseListVersion != "3.0" || len(list.Licenses) != 2 {
+		t.Errorf("LicenseList = %+v, %v", list, err)
+	}
+	if d, err := src.LicenseDetails(ctx, "MIT"); err != nil || d.Name != "MIT License" {
+		t.Errorf("LicenseDetails = %+v, %v", d, err)
+	}
+	for _, id := range []string{"Nope", "../licenses", "a/b", ""} {
+		if _, err := src.LicenseDetails(ctx, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("LicenseDetails(%q) err = %v, want ErrNotFound", id, err)
+		}
+	}
+	if _, err := src.LicenseDetails(ctx, "Broken"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("LicenseDetails(Broken) err = %v, want a JSON error", err)
+	}
+	if list, err := src.ExceptionList(ctx); err != nil || list.Exceptions[0].LicenseExceptionID != "LLVM-exception" {
+		t.Errorf("ExceptionList = %+v, %v", list, err)
+	}
+	if d, err := src.ExceptionDetails(ctx, "LLVM-exception"); err != nil || d.LicenseExceptionText != "text" {
+		t.Errorf("ExceptionDetails = %+v, %v", d, err)
+	}
+}
+
+func TestNewDirSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "details"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "licenses.json"), []byte(goodJSON), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "details", "MIT.json"), []byte(goodDetailsJSON), 0644)
+
+	src := NewDirSource(dir)
+	if list, err := src.LicenseList(context.Background()); err != nil || len(list.Licenses) != 2 {
+		t.Errorf("LicenseList = %+v, %v", list, err)
+	}
+	if d, err := src.LicenseDetails(context.Background(), "MIT"); err != nil || d.LicenseID != "MIT" {
+		t.Errorf("LicenseDetails = %+v, %v", d, err)
+	}
+	if _, err := NewDirSource(filepath.Join(dir, "missing")).LicenseList(context.Background()); err == nil {
+		t.Error("LicenseList on a missing directory: expected error")
+	}
+}
+
+func TestMemorySource(t *testing.T) {
+	src := &MemorySource{
+		Licenses:           &LicenseList{Licenses: []License{{LicenseID: "MIT"}}},
+		LicenseDetailsByID: map[string]*LicenseDetails{"MIT": {LicenseText: "text"}},
+	}
+	ctx := context.Background()
+	if d, err := src.LicenseDetails(ctx, "MIT"); err != nil || d.LicenseText != "text" {
+		t.Errorf("LicenseDetails = %+v, %v", d, err)
+	}
+	if _, err := src.LicenseDetails(ctx, "X"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LicenseDetails(X) err = %v, want ErrNotFound", err)
+	}
+	if _, err := src.ExceptionList(ctx); err == nil {
+		t.Error("ExceptionList with nil list: expected error")
+	}
+	boom := errors.New("boom")
+	src.Err = boom
+	if _, err := src.LicenseList(ctx); !errors.Is(err, boom) {
+		t.Errorf("LicenseList with Err = %v, want boom", err)
+	}
+}
+
+func TestFallbackSource(t *testing.T) {
+	down := &MemorySource{Err: errors.New("connection refused")}
+	snap := &MemorySource{
+		Licenses:           &LicenseList{LicenseListVersion: "snap"},
+		LicenseDetailsByID: map[string]*LicenseDetails{"MIT": {LicenseText: "snap"}},
+	}
+	var fellBack int
+	src := &FallbackSource{Primary: down, Fallback: snap, OnFallback: func(error) { fellBack++ }}
+	ctx := context.Background()
+
+	if list, err := src.LicenseList(ctx); err != nil || list.LicenseListVersion != "snap" {
+		t.Errorf("LicenseList = %+v, %v", list, err)
+	}
+	if d, err := src.LicenseDetails(ctx, "MIT"); err != nil || d.LicenseText != "snap" {
+		t.Errorf("LicenseDetails = %+v, %v", d, err)
+	}
+	if fellBack != 2 {
+		t.Errorf("OnFallback called %d times, want 2", fellBack)
+	}
+	// fallback cannot answer: the primary error is kept
+	if _, err := src.ExceptionList(ctx); err == nil || err.Error() != "connection refused" {
+		t.Errorf("ExceptionList err = %v, want primary error", err)
+	}
+	// a primary 404 is authoritative
+	up := &MemorySource{LicenseDetailsByID: map[string]*LicenseDetails{}}
+	src = &FallbackSource{Primary: up, Fallback: snap}
+	if _, err := src.LicenseDetails(ctx, "MIT"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LicenseDetails after primary 404 = %v, want ErrNotFound", err)
+	}
+}
+
+func TestHTTPSource_Pinned(t *testing.T) {
+	p, err := (&HTTPSource{ExceptionsURL: "https://mirror.example/{version}/exceptions.json"}).Pinned("3.24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ListURL != "https://raw.githubusercontent.com/spdx/license-list-data/v3.24.0/json/licenses.json" {
+		t.Errorf("ListURL = %q", p.ListURL)
+	}
+	if p.ExceptionsURL != "https://mirror.example/v3.24.0/exceptions.json" {
+		t.Errorf("ExceptionsURL = %q", p.ExceptionsURL)
+	}
+	p, err = (&HTTPSource{ListMirrors: []string{"https://mirror.example/{version}/licenses.json"}}).Pinned("3.25")
+	if err != nil || len(p.ListMirrors) != 1 || p.ListMirrors[0] != "https://mirror.example/v3.25.0/licenses.json" {
+		t.Errorf("ListMirrors = %v, %v", p.ListMirrors, err)
+	}
+	if _, err := (&HTTPSource{ListURL: "https://mirror.example/licenses.json"}).Pinned("3.24"); err == nil {
+		t.Error("Pinned: expected error for a URL without {version}")
+	}
+}
@@ -0,0 +1,156 @@
+package spdx
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Candidate is a known ID with the other texts it may be looked up by: its full name and the user's
+// aliases for it.
+type Candidate struct {
+	ID      string
+	Name    string
+	Aliases []string
+}
+
+// minSuggestScore is the similarity below which a candidate is not worth suggesting.
+const minSuggestScore = 0.5
+
+// MatchFold returns the ID in ids equal to id ignoring case, as SPDX IDs are matched (mit → MIT).
+func MatchFold(ids []string, id string) (string, bool) {
+	for _, known := range ids {
+		if strings.EqualFold(known, id) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// Suggest returns the IDs of up to n candidates closest to query, best first. The ID and aliases of a
+// candidate are compared by edit distance ignoring case and punctuation (Apache2 vs Apache-2.0) and by
+// the overlap of their words and numbers; its name by the overlap alone (apache license vs Apache
+// License 2.0), as names are too long for edits to mean much. A different version number (gpl 3 vs
+// GPL-2.0) halves the score.
+func Suggest(query string, candidates []Candidate, n int) []string {
+	best := scores(query, candidates)
+	var ids []string
+	for id, s := range best {
+		if s >= minSuggestScore {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if best[ids[i]] != best[ids[j]] {
+			return best[ids[i]] > best[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > n {
+		ids = ids[:n]
+	}
+	return ids
+}
+
+// scores returns the similarity in [0, 1] of query to each candidate, as ranked by Suggest.
+func scores(query string, candidates []Candidate) map[string]float64 {
+	q := similarityKey(query)
+	best := make(map[string]float64)
+	for _, c := range candidates {
+		for _, text := range append([]string{c.ID}, c.Aliases...) {
+			best[c.ID] = max(best[c.ID], q.similarity(similarityKey(text), true))
+		}
+		if c.Name != "" {
+			best[c.ID] = max(best[c.ID], q.similarity(similarityKey(c.Name), false))
+		}
+	}
+	return best
+}
+
+// key is a text prepared for comparison: its lower-case letters and digits, its tokens (runs of
+// letters or of digits) and the first run of digits, taken as its version.
+type key struct {
+	compact []rune
+	tokens  map[string]bool
+	version string
+}
+
+func similarityKey(s string) key {
+	k := key{tokens: make(map[string]bool)}
+	var tok []rune
+	flush := func() {
+		if len(tok) > 0 {
+			if k.version == "" && unicode.IsDigit(tok[0]) {
+				k.version = string(tok)
+			}
+			k.tokens[string(tok)] = true
+			tok = tok[:0]
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(tok) > 0 && unicode.IsDigit(tok[len(tok)-1]) != unicode.IsDigit(r) {
+			flush()
+		}
+		tok = append(tok, r)
+		k.compact = append(k.compact, r)
+	}
+	flush()
+	return k
+}
+
+// similarity is in [0, 1] for query a: the Jaccard index of the tokens, raised to at least one half
+// when b has all of a's tokens (lgpl vs LGPL-2.1-only) or, with edits, to the edit similarity relative
+// to the shorter text; halved when both have a version and they differ.
+func (a key) similarity(b key, edits bool) float64 {
+	var s float64
+	if shortest := min(len(a.compact), len(b.compact)); edits && shortest > 0 {
+		s = max(0, 1-float64(editDistance(a.compact, b.compact))/float64(shortest))
+	}
+	shared := 0
+	for t := range a.tokens {
+		if b.tokens[t] {
+			shared++
+		}
+	}
+	if union := len(a.tokens) + len(b.tokens) - shared; union > 0 {
+		jaccard := float64(shared) / float64(union)
+		if shared == len(a.tokens) {
+			jaccard = 0.5 + jaccard/2
+		}
+		s = max(s, jaccard)
+	}
+	if a.version != "" && b.version != "" && a.version != b.version {
+		s /= 2
+	}
+	return s
+}
+
+// editDistance is the optimal string alignment distance: insertions, deletions, substitutions and
+// transpositions of adjacent runes (mti → mit) each cost 1.
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
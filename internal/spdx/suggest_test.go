@@ -0,0 +1,55 @@
+package spdx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchFold(t *testing.T) {
+	ids := []string{"MIT", "Apache-2.0"}
+	if got, ok := MatchFold(ids, "apache-2.0"); !ok || got != "Apache-2.0" {
+		t.Errorf("MatchFold(apache-2.0) = %q, %v", got, ok)
+	}
+	if _, ok := MatchFold(ids, "Apache2"); ok {
+		t.Error("MatchFold(Apache2): matched")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []Candidate{
+		{ID: "MIT", Name: "MIT License"},
+		{ID: "Apache-2.0", Name: "Apache License 2.0", Aliases: []string{"asl"}},
+		{ID: "GPL-2.0-only", Name: "GNU General Public License v2.0 only"},
+		{ID: "GPL-3.0-only", Name: "GNU General Public License v3.0 only"},
+		{ID: "LGPL-2.1-only", Name: "GNU Lesser General Public License v2.1 only"},
+		{ID: "ISC", Name: "ISC License"},
+	}
+	for query, want := range map[string][]string{
+		"Apache2":        {"Apache-2.0"},
+		"apahce":         {"Apache-2.0"},
+		"apache license": {"Apache-2.0"},
+		"asll":           {"Apache-2.0"},
+		"mti":            {"MIT"},
+		"gpl3":           {"GPL-3.0-only"},
+		"lgpl":           {"LGPL-2.1-only"},
+		"zlib":           nil,
+	} {
+		if got := Suggest(query, candidates, 3); !reflect.DeepEqual(got, want) {
+			t.Errorf("Suggest(%q) = %v, want %v", query, got, want)
+		}
+	}
+	if got := Suggest("license", candidates, 2); len(got) != 2 {
+		t.Errorf("Suggest(license, n=2) = %v, want 2 IDs", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{{"mit", "mit", 0}, {"mti", "mit", 1}, {"apache2", "apache20", 1}, {"", "isc", 3}, {"kitten", "sitting", 3}} {
+		if got := editDistance([]rune(tc.a), []rune(tc.b)); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package spdx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TemplateVar is a <<var;name=...;original=...;match=...>> of an SPDX standard license template:
+// a replaceable span such as the MIT copyright line. Original is the text as it appears in licenseText.
+type TemplateVar struct {
+	Name     string
+	Original string
+	Match    string
+}
+
+// TemplateNode is one node of a parsed template: literal Text, a Var, or an Optional block (Children).
+type TemplateNode struct {
+	Text     string
+	Var      *TemplateVar
+	Optional bool
+	Children []TemplateNode
+}
+
+// Template is a parsed SPDX standardLicenseTemplate.
+type Template struct {
+	Nodes []TemplateNode
+}
+
+// ParseTemplate parses the SPDX template markup: <<var;...>> and nested <<beginOptional>> ... <<endOptional>>
+// (optional blocks may carry attributes, e.g. <<beginOptional;name="title">>). Unknown rules are an error.
+func ParseTemplate(s string) (*Template, error) {
+	nodes, _, closed, err := parseNodes(s)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		return nil, fmt.Errorf("spdx: template: unexpected <<endOptional>>")
+	}
+	return &Template{Nodes: nodes}, nil
+}
+
+// parseNodes parses s up to the first unmatched <<endOptional>> (closed=true, rest follows it) or end of input.
+func parseNodes(s string) (nodes []TemplateNode, rest string, closed bool, err error) {
+	for s != "" {
+		i := strings.Index(s, "<<")
+		if i < 0 {
+			nodes = append(nodes, TemplateNode{Text: s})
+			break
+		}
+		if i > 0 {
+			nodes = append(nodes, TemplateNode{Text: s[:i]})
+		}
+		j := strings.Index(s[i:], ">>")
+		if j < 0 {
+			return nil, "", false, fmt.Errorf("spdx: template: unterminated rule %.20q", s[i:])
+		}
+		rule := s[i+2 : i+j]
+		s = s[i+j+2:]
+		kind, attrs, _ := strings.Cut(rule, ";")
+		switch strings.TrimSpace(kind) {
+		case "var":
+			nodes = append(nodes, TemplateNode{Var: parseVar(attrs)})
+		case "beginOptional":
+			children, after, ok, err := parseNodes(s)
+			if err != nil {
+				return nil, "", false, err
+			}
+			if !ok {
+				return nil, "", false, fmt.Errorf("spdx: template: unterminated <<beginOptional>>")
+			}
+			nodes = append(nodes, TemplateNode{Optional: true, Children: children})
+			s = after
+		case "endOptional":
+			return nodes, s, true, nil
+		default:
+			return nil, "", false, fmt.Errorf("spdx: template: unknown rule %q", kind)
+		}
+	}
+	return nodes, "", false, nil
+}
+
+// attrRe matches key="value" pairs of a template rule; values may contain escaped quotes.
+var attrRe = regexp.MustCompile(`(\w+)\s*=\s*"((?:[^"\\]|\\.)*)"`)
+
+func parseVar(attrs string) *TemplateVar {
+	v := &TemplateVar{}
+	for _, m := range attrRe.FindAllStringSubmatch(attrs, -1) {
+		val := strings.ReplaceAll(m[2], `\"`, `"`)
+		switch m[1] {
+		case "name":
+			v.Name = val
+		case "original":
+			v.Original = val
+		case "match":
+			v.Match = val
+		}
+	}
+	return v
+}
+
+// Vars returns every var of t in document order, including those inside optional blocks.
+func (t *Template) Vars() []*TemplateVar {
+	var out []*TemplateVar
+	var walk func([]TemplateNode)
+	walk = func(nodes []TemplateNode) {
+		for _, n := range nodes {
+			if n.Var != nil {
+				out = append(out, n.Var)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(t.Nodes)
+	return out
+}
+
+// Values are the copyright values substituted for license placeholders. Empty fields are left as-is.
+type Values struct {
+	Holder  string
+	Year    string
+	Project string
+	Email   string
+}
+
+// IsZero reports whether no value is set.
+func (v Values) IsZero() bool {
+	return v == Values{}
+}
+
+// knownPlaceholders are common placeholders that appear in licenseText outside of template vars
+// (e.g. the Apache-2.0 appendix "Copyright [yyyy] [name of copyright owner]").
+var knownPlaceholders = []string{
+	"<year>", "[yyyy]", "<yyyy>", "[year]",
+	"<copyright holders>", "<copyright holder>", "[name of copyright owner]", "<owner>", "<name of author>",
+}
+
+// placeholderRe matches <...> and [...] spans inside a var's original text.
+var placeholderRe = regexp.MustCompile(`<[^<>\n]+>|\[[^\[\]\n]+\]`)
+
+// Fill returns d.LicenseText with copyright placeholders replaced by v (see FillText).
+func Fill(d *LicenseDetails, v Values) string {
+	return FillText(d.LicenseText, d.StandardLicenseTemplate, v)
+}
+
+// FillHeader returns d.StandardLicenseHeader with copyright placeholders replaced by v (see FillText).
+func FillHeader(d *LicenseDetails, v Values) string {
+	return FillText(d.StandardLicenseHeader, d.StandardLicenseHeaderTemplate, v)
+}
+
+// FillText returns text with copyright placeholders replaced by v. Placeholders are the <...>/[...]
+// spans inside the vars of template (e.g. MIT's "Copyright (c) <year> <copyright holders>") plus a few
+// well-known ones; each is classified as year, holder, email or project by its wording. When the
+// template is empty or malformed only the well-known placeholders are used. With an email and a
+// holder placeholder, the holder becomes "Holder <email>".
+func FillText(text, template string, v Values) string {
+	if v.IsZero() {
+		return text
+	}
+	candidates := append([]string(nil), knownPlaceholders...)
+	if template != "" {
+		if t, err := ParseTemplate(template); err == nil {
+			for _, tv := range t.Vars() {
+				candidates = append(candidates, placeholderRe.FindAllString(tv.Original, -1)...)
+			}
+		}
+	}
+	holder := v.Holder
+	if holder != "" && v.Email != "" {
+		holder += " <" + v.Email + ">"
+	}
+	done := make(map[string]bool)
+	for _, p := range candidates {
+		if done[p] {
+			continue
+		}
+		done[p] = true
+		var val string
+		switch classifyPlaceholder(p) {
+		case "year":
+			val = v.Year
+		case "holder":
+			val = holder
+		case "email":
+			val = v.Email
+		case "project":
+			val = v.Project
+		}
+		if val != "" {
+			text = strings.ReplaceAll(text, p, val)
+		}
+	}
+	return text
+}
+
+// classifyPlaceholder maps a placeholder like "<copyright holders>" to year, holder, email, project or "".
+func classifyPlaceholder(p string) string {
+	s := strings.ToLower(p)
+	switch {
+	case strings.Contains(s, "year") || strings.Contains(s, "yyyy"):
+		return "year"
+	case strings.Contains(s, "email") || strings.Contains(s, "e-mail"):
+		return "email"
+	case strings.Contains(s, "program") || strings.Contains(s, "project") || strings.Contains(s, "software name"):
+		return "project"
+	case strings.Contains(s, "holder") || strings.Contains(s, "owner") || strings.Contains(s, "author") ||
+		strings.Contains(s, "organization") || strings.Contains(s, "fullname") || strings.Contains(s, "name of"):
+		return "holder"
+	}
+	return ""
+}
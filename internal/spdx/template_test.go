@@ -0,0 +1,116 @@
+package spdx
+
+import (
+	"strings"
+	"testing"
+)
+
+const mitTemplate = `<<beginOptional>> MIT License<<endOptional>>
+
+<<var;name="copyright";original="Copyright (c) <year> <copyright holders>";match=".{0,5000}">>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of <<var;name="software";original="this software";match="this software|the software">> ...`
+
+const mitText = "MIT License\n\nCopyright (c) <year> <copyright holders>\n\nPermission is hereby granted, free of charge, to any person obtaining a copy of this software ..."
+
+func TestParseTemplate_VarsAndOptional(t *testing.T) {
+	tmpl, err := ParseTemplate(mitTemplate)
+	if err != nil {
+		t.Fatalf("ParseTemplate: %v", err)
+	}
+	if len(tmpl.Nodes) == 0 || !tmpl.Nodes[0].Optional || tmpl.Nodes[0].Children[0].Text != " MIT License" {
+		t.Errorf("first node = %+v, want optional title", tmpl.Nodes[0])
+	}
+	vars := tmpl.Vars()
+	if len(vars) != 2 {
+		t.Fatalf("vars = %+v", vars)
+	}
+	if vars[0].Name != "copyright" || vars[0].Original != "Copyright (c) <year> <copyright holders>" || vars[0].Match != ".{0,5000}" {
+		t.Errorf("vars[0] = %+v", vars[0])
+	}
+	if vars[1].Name != "software" || vars[1].Original != "this software" {
+		t.Errorf("vars[1] = %+v", vars[1])
+	}
+}
+
+func TestParseTemplate_NestedOptionalWithAttrs(t *testing.T) {
+	tmpl, err := ParseTemplate(`a<<beginOptional;name="x">>b<<beginOptional>>c<<var;name="q";original="say \"hi\"";match=".+">><<endOptional>><<endOptional>>d`)
+	if err != nil {
+		t.Fatalf("ParseTemplate: %v", err)
+	}
+	if len(tmpl.Nodes) != 3 || tmpl.Nodes[2].Text != "d" {
+		t.Fatalf("nodes = %+v", tmpl.Nodes)
+	}
+	vars := tmpl.Vars()
+	if len(vars) != 1 || vars[0].Original != `say "hi"` {
+		t.Errorf("vars = %+v", vars)
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	for _, s := range []string{
+		"a<<beginOptional>>b",
+		"a<<endOptional>>",
+		"a<<var;name=\"x\"",
+		"a<<bogus>>",
+	} {
+		if _, err := ParseTemplate(s); err == nil {
+			t.Errorf("ParseTemplate(%q): expected error", s)
+		}
+	}
+}
+
+func TestFill_FromTemplateVars(t *testing.T) {
+	d := &LicenseDetails{LicenseText: mitText, StandardLicenseTemplate: mitTemplate}
+	got := Fill(d, Values{Holder: "Acme Corp", Year: "2026"})
+	if !strings.Contains(got, "Copyright (c) 2026 Acme Corp\n") {
+		t.Errorf("Fill = %q", got)
+	}
+	if strings.Contains(got, "<year>") || strings.Contains(got, "<copyright holders>") {
+		t.Errorf("placeholders left in %q", got)
+	}
+}
+
+func TestFill_EmailAppendedToHolder(t *testing.T) {
+	d := &LicenseDetails{LicenseText: mitText, StandardLicenseTemplate: mitTemplate}
+	got := Fill(d, Values{Holder: "Jane Doe", Year: "2025", Email: "jane@example.com"})
+	if !strings.Contains(got, "Copyright (c) 2025 Jane Doe <jane@example.com>") {
+		t.Errorf("Fill = %q", got)
+	}
+}
+
+func TestFill_KnownPlaceholdersWithoutTemplate(t *testing.T) {
+	d := &LicenseDetails{LicenseText: "Copyright [yyyy] [name of copyright owner]\n\nLicensed under the Apache License"}
+	got := Fill(d, Values{Holder: "Acme", Year: "2020-2026"})
+	if got != "Copyright 2020-2026 Acme\n\nLicensed under the Apache License" {
+		t.Errorf("Fill = %q", got)
+	}
+}
+
+func TestFill_ProjectPlaceholder(t *testing.T) {
+	d := &LicenseDetails{
+		LicenseText:             "<program>  Copyright (C) <year>  <name of author>",
+		StandardLicenseTemplate: `<<var;name="program";original="<program>";match=".+">>  Copyright (C) <<var;name="c";original="<year>  <name of author>";match=".+">>`,
+	}
+	got := Fill(d, Values{Project: "widget", Year: "2026"})
+	if got != "widget  Copyright (C) 2026  <name of author>" {
+		t.Errorf("Fill = %q (unset holder must stay a placeholder)", got)
+	}
+}
+
+func TestFill_ZeroValuesUnchanged(t *testing.T) {
+	d := &LicenseDetails{LicenseText: mitText, StandardLicenseTemplate: mitTemplate}
+	if got := Fill(d, Values{}); got != mitText {
+		t.Errorf("Fill with no values changed text: %q", got)
+	}
+}
+
+func TestFillHeader(t *testing.T) {
+	d := &LicenseDetails{
+		StandardLicenseHeader: "<one line to give the program's name and a brief idea of what it does.>\nCopyright (C) <year>  <name of author>\n",
+	}
+	got := FillHeader(d, Values{Holder: "Jane Doe", Year: "2026"})
+	if got != "<one line to give the program's name and a brief idea of what it does.>\nCopyright (C) 2026  Jane Doe\n" {
+		t.Errorf("FillHeader = %q", got)
+	}
+}
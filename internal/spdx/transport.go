@@ -0,0 +1,71 @@
+package spdx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// TransportOptions configures NewTransport for networks that need an explicit proxy, a private root
+// CA or client certificates. The zero value behaves like http.DefaultTransport.
+type TransportOptions struct {
+	// Proxy is the proxy URL for every request; empty means HTTP_PROXY / HTTPS_PROXY / NO_PROXY.
+	Proxy string
+	// CAFiles are PEM files of extra root CAs, trusted in addition to the system pool.
+	CAFiles []string
+	// CertFile and KeyFile are a PEM client certificate and its key. KeyFile may be empty when
+	// CertFile holds both.
+	CertFile string
+	KeyFile  string
+}
+
+// NewTransport returns an http.Transport with the proxy, root CAs and client certificate of opts.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	if opts.KeyFile != "" && opts.CertFile == "" {
+		return nil, fmt.Errorf("spdx: client key %s given without a certificate", opts.KeyFile)
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("spdx: invalid proxy URL %q", opts.Proxy)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if len(opts.CAFiles) == 0 && opts.CertFile == "" {
+		return t, nil
+	}
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(opts.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, f := range opts.CAFiles {
+			pem, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("spdx: read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("spdx: no PEM certificates in CA file %s", f)
+			}
+		}
+		tc.RootCAs = pool
+	}
+	if opts.CertFile != "" {
+		key := opts.KeyFile
+		if key == "" {
+			key = opts.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, key)
+		if err != nil {
+			return nil, fmt.Errorf("spdx: load client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	t.TLSClientConfig = tc
+	return t, nil
+}
@@ -0,0 +1,80 @@
+package spdx
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTransport_CAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer srv.Close()
+
+	// Without the server's CA the fetch fails; with it as an extra root it succeeds.
+	c := &Client{Retries: -1}
+	if _, _, err := fetchLicenseList(context.Background(), c, []string{srv.URL}, Validators{}); err == nil {
+		t.Fatal("fetch without CA: expected a certificate error")
+	}
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(ca, pemBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTransport(TransportOptions{CAFiles: []string{ca}})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	c = &Client{Retries: -1, Transport: tr}
+	if _, _, err := fetchLicenseList(context.Background(), c, []string{srv.URL}, Validators{}); err != nil {
+		t.Errorf("fetch with CA: %v", err)
+	}
+}
+
+func TestNewTransport_Proxy(t *testing.T) {
+	var got string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.String() // a proxy sees the absolute request URL
+		_, _ = w.Write([]byte(goodJSON))
+	}))
+	defer proxy.Close()
+
+	tr, err := NewTransport(TransportOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	c := &Client{Retries: -1, Transport: tr}
+	if _, _, err := fetchLicenseList(context.Background(), c, []string{"http://spdx.example/licenses.json"}, Validators{}); err != nil {
+		t.Fatalf("fetch via proxy: %v", err)
+	}
+	if got != "http://spdx.example/licenses.json" {
+		t.Errorf("proxy saw %q", got)
+	}
+}
+
+func TestNewTransport_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, opts := range map[string]TransportOptions{
+		"proxy":          {Proxy: "not a url"},
+		"missing CA":     {CAFiles: []string{filepath.Join(dir, "missing.pem")}},
+		"CA without PEM": {CAFiles: []string{notPEM}},
+		"bad cert":       {CertFile: notPEM},
+		"key only":       {KeyFile: notPEM},
+	} {
+		if _, err := NewTransport(opts); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := NewTransport(TransportOptions{}); err != nil {
+		t.Errorf("zero options: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package spdx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionPlaceholder in a configured URL is replaced by the license-list-data tag of the pinned
+// license list version ("main" when none is pinned).
+const VersionPlaceholder = "{version}"
+
+// defaultBranch is the license-list-data path segment the Default* URLs use.
+const defaultBranch = "/license-list-data/main/"
+
+var shortVersion = regexp.MustCompile(`^3\.(\d+)$`)
+
+// ListVersionTag returns the license-list-data git tag for a license list version: "3.23" → "v3.23",
+// "3.24" → "v3.24.0" (releases are tagged v3.N up to 3.23 and v3.N.0 from 3.24 on). A leading "v"
+// and full tags such as "3.24.1" are accepted as is.
+func ListVersionTag(version string) string {
+	v := strings.TrimPrefix(version, "v")
+	if m := shortVersion.FindStringSubmatch(v); m != nil {
+		if n, _ := strconv.Atoi(m[1]); n >= 24 {
+			v += ".0"
+		}
+	}
+	return "v" + v
+}
+
+// SameListVersion reports whether two license list versions name the same release ("3.24" and "v3.24.0").
+func SameListVersion(a, b string) bool {
+	return ListVersionTag(a) == ListVersionTag(b)
+}
+
+// VersionedURL returns url pinned to the given license list version: VersionPlaceholder is replaced
+// by the tag, or the "main" branch of a license-list-data URL is swapped for it. An empty version
+// leaves url on main. It is an error to pin a URL that has neither.
+func VersionedURL(url, version string) (string, error) {
+	if version == "" {
+		return strings.ReplaceAll(url, VersionPlaceholder, "main"), nil
+	}
+	tag := ListVersionTag(version)
+	switch {
+	case strings.Contains(url, VersionPlaceholder):
+		return strings.ReplaceAll(url, VersionPlaceholder, tag), nil
+	case strings.Contains(url, defaultBranch):
+		return strings.Replace(url, defaultBranch, "/license-list-data/"+tag+"/", 1), nil
+	}
+	return "", fmt.Errorf("cannot pin license list version %s: URL %s has no %s placeholder", version, url, VersionPlaceholder)
+}
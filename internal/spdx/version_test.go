@@ -0,0 +1,42 @@
+package spdx
+
+import "testing"
+
+func TestListVersionTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"3.23":    "v3.23",
+		"3.24":    "v3.24.0",
+		"v3.25":   "v3.25.0",
+		"3.24.1":  "v3.24.1",
+		"v3.24.0": "v3.24.0",
+		"3.9":     "v3.9",
+	} {
+		if got := ListVersionTag(in); got != want {
+			t.Errorf("ListVersionTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !SameListVersion("3.24", "v3.24.0") || SameListVersion("3.24", "3.25") {
+		t.Error("SameListVersion mismatch")
+	}
+}
+
+func TestVersionedURL(t *testing.T) {
+	tests := []struct {
+		url, version, want string
+		wantErr            bool
+	}{
+		{DefaultListURL, "", DefaultListURL, false},
+		{DefaultListURL, "3.24", "https://raw.githubusercontent.com/spdx/license-list-data/v3.24.0/json/licenses.json", false},
+		{DefaultDetailsURLTemplate, "3.22", "https://raw.githubusercontent.com/spdx/license-list-data/v3.22/json/details/{id}.json", false},
+		{"https://mirror.example/{version}/licenses.json", "3.24", "https://mirror.example/v3.24.0/licenses.json", false},
+		{"https://mirror.example/{version}/licenses.json", "", "https://mirror.example/main/licenses.json", false},
+		{"https://mirror.example/licenses.json", "", "https://mirror.example/licenses.json", false},
+		{"https://mirror.example/licenses.json", "3.24", "", true},
+	}
+	for _, tt := range tests {
+		got, err := VersionedURL(tt.url, tt.version)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("VersionedURL(%q, %q) = %q, %v; want %q (err %v)", tt.url, tt.version, got, err, tt.want, tt.wantErr)
+		}
+	}
+}